@@ -1,36 +1,109 @@
 package main
 
 import (
+	"bytes"
+	_ "embed"
+	"flag"
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/jjviana/ml4devs/pkg/ml"
 )
 
+//defaultPrecision is the default number of decimal digits of the printed values,
+//enough to tell apart predictions that only differ close to a label (such as 0.999001 and 0.999009)
+const defaultPrecision = 6
+
+//defaultModel is used when no model file is provided
+//go:embed white.model
+var defaultModel []byte
+
 func main() {
 
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: test <model file> <dataset>")
+	modelFile := flag.String("model", "", "model file (defaults to the embedded white wine model)")
+	precision := flag.Int("precision", defaultPrecision, "number of decimal digits in the printed labels and predictions")
+	printPairs := flag.Bool("pairs", true, "print the label,prediction pair of every example")
+	metrics := flag.String("metrics", "", "comma separated list of metrics to print (rmse, mae, r2, accuracy, macro_f1, micro_f1, pr_auc)")
+	flag.Parse()
+
+	modelFileName, datasetFile, err := parseArgs(*modelFile, flag.Args())
+	if err == nil && *precision < 0 {
+		err = fmt.Errorf("the precision can not be negative, found %d", *precision)
+	}
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: test [-model <model file>] [-precision <digits>] [-pairs=false] [-metrics <metric,...>] <dataset>")
+		fmt.Println("       test [-precision <digits>] [-pairs=false] [-metrics <metric,...>] <model file> <dataset>")
 		return
 	}
 
-	model, err := ml.LoadModel(os.Args[1])
+	model, err := loadModel(modelFileName)
 	if err != nil {
 		fmt.Printf("Error loading model: %s\n", err)
 		return
 	}
 
-	datasetFile := os.Args[2]
+	fmt.Println(model.Summary())
+
 	dataSet, err := ml.ReadCSVDataSet(datasetFile)
 	if err != nil {
 		fmt.Printf("Error loading dataset: %s\n", err)
 		return
 	}
 
-	loss := ml.Test(model, dataSet, func(example ml.Example, prediction float64) {
-		fmt.Printf("%.03f,%.03f\n", example.Label, prediction)
-	})
+	//Metrics are evaluated before Test, which normalizes the dataset in place
+	report := ml.RegressionMetrics(model, dataSet)
+	var results map[string]float64
+	var metricNames []string
+	if *metrics != "" {
+		metricNames = strings.Split(*metrics, ",")
+		results, err = ml.EvaluateAll(model, dataSet, metricNames)
+		if err != nil {
+			fmt.Printf("Error evaluating metrics: %s\n", err)
+			return
+		}
+	}
+
+	if *printPairs {
+		ml.Test(model, dataSet, func(example ml.Example, prediction float64) {
+			fmt.Println(formatPair(example.Label, prediction, *precision))
+		})
+		fmt.Println()
+	}
+
+	fmt.Printf("MSE: %.*f\nRMSE: %.*f\nMAE: %.*f\nR2: %.*f\n", *precision, report.MSE,
+		*precision, report.RMSE, *precision, report.MAE, *precision, report.R2)
+	for _, name := range metricNames {
+		fmt.Printf("%s: %.*f\n", name, *precision, results[name])
+	}
 
-	fmt.Printf("\nLoss: %.03f\n", loss)
+}
 
+//parseArgs returns the model and dataset files from the -model flag and the positional arguments:
+//either <dataset> (the model file coming from the flag, if any) or <model file> <dataset>
+func parseArgs(modelFlag string, args []string) (string, string, error) {
+	switch len(args) {
+	case 1:
+		return modelFlag, args[0], nil
+	case 2:
+		if modelFlag != "" {
+			return "", "", fmt.Errorf("the model file can not be provided both with -model and as an argument")
+		}
+		return args[0], args[1], nil
+	default:
+		return "", "", fmt.Errorf("expected 1 or 2 arguments, found %d", len(args))
+	}
+}
+
+//formatPair formats a label and its prediction as a CSV line, with precision decimal digits
+func formatPair(label, prediction float64, precision int) string {
+	return fmt.Sprintf("%.*f,%.*f", precision, label, precision, prediction)
+}
+
+//loadModel loads the model from the provided file, or the embedded model if none is provided
+func loadModel(fileName string) (ml.Model, error) {
+	if fileName == "" {
+		return ml.LoadModelFrom(bytes.NewReader(defaultModel))
+	}
+	return ml.LoadModel(fileName)
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jjviana/ml4devs/pkg/ml"
+)
+
+func TestParseArgs(t *testing.T) {
+
+	tests := []struct {
+		modelFlag     string
+		args          []string
+		modelFile     string
+		datasetFile   string
+		expectedError bool
+	}{
+		{"", []string{"data.csv"}, "", "data.csv", false},
+		{"flag.model", []string{"data.csv"}, "flag.model", "data.csv", false},
+		{"", []string{"arg.model", "data.csv"}, "arg.model", "data.csv", false},
+		{"flag.model", []string{"arg.model", "data.csv"}, "", "", true},
+		{"", []string{}, "", "", true},
+		{"", []string{"a", "b", "c"}, "", "", true},
+	}
+	for _, test := range tests {
+		modelFile, datasetFile, err := parseArgs(test.modelFlag, test.args)
+		if (err != nil) != test.expectedError {
+			t.Fatalf("%q %v: unexpected error %v", test.modelFlag, test.args, err)
+		}
+		if modelFile != test.modelFile || datasetFile != test.datasetFile {
+			t.Fatalf("%q %v: expected %q %q, found %q %q", test.modelFlag, test.args,
+				test.modelFile, test.datasetFile, modelFile, datasetFile)
+		}
+	}
+}
+
+func TestEmbeddedModelPredicts(t *testing.T) {
+
+	model, err := loadModel("")
+	if err != nil {
+		t.Fatalf("error loading the embedded model: %v", err)
+	}
+	if len(model.Coeficients) == 0 {
+		t.Fatalf("embedded model has no coefficients")
+	}
+
+	example := ml.Example{Features: make([]float64, len(model.Coeficients))}
+	if prediction := ml.Predict(model, example); math.IsNaN(prediction) || math.IsInf(prediction, 0) {
+		t.Fatalf("expected a finite prediction, found %v", prediction)
+	}
+}
+
+func TestFormatPairDefaultPrecision(t *testing.T) {
+
+	first, second := formatPair(1, 0.999001, defaultPrecision), formatPair(1, 0.999009, defaultPrecision)
+	if first == second {
+		t.Fatalf("expected distinct renderings, found %q for both", first)
+	}
+	if first != "1.000000,0.999001" {
+		t.Fatalf("expected 1.000000,0.999001, found %q", first)
+	}
+	//The previous default of 3 digits could not tell them apart
+	if formatPair(1, 0.999001, 3) != formatPair(1, 0.999009, 3) {
+		t.Fatalf("expected identical renderings with 3 digits")
+	}
+}
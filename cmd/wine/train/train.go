@@ -1,21 +1,37 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"sort"
+	"syscall"
 
 	"github.com/jjviana/ml4devs/pkg/ml"
 )
 
 func main() {
 
-	if len(os.Args) != 3 {
-		fmt.Printf("Usage: wine <training file> <output file> \n")
+	dryRun := flag.Bool("dry-run", false, "load the dataset and print its summary and the training configuration, without training")
+	flag.Parse()
+
+	if flag.NArg() != 2 && !(*dryRun && flag.NArg() == 1) {
+		fmt.Printf("Usage: wine [-dry-run] <training file | - for stdin> <output file> \n")
 		return
 	}
-	trainingFileName := os.Args[1]
+	trainingFileName := flag.Arg(0)
+
+	if trainingFileName != "-" {
+		datasetType, err := ml.DetectDatasetType(trainingFileName)
+		if err == nil && datasetType == ml.TextDataset {
+			fmt.Printf("Warning: %s looks like a text dataset, only numeric features are supported\n", trainingFileName)
+		}
+	}
 
-	dataSet, err := ml.ReadCSVDataSet(trainingFileName)
+	dataSet, stats, err := readDataSet(trainingFileName)
 	if err != nil {
 		fmt.Printf("Error reading dataset: %s \n", err)
 		return
@@ -23,17 +39,70 @@ func main() {
 
 	fmt.Printf("Read %d training examples\n", len(dataSet))
 
-	model, err := ml.Train(dataSet, learningRate, numEpochs)
+	if *dryRun {
+		printSummary(os.Stdout, dataSet, stats)
+		return
+	}
+
+	//Ctrl-C (or SIGTERM) stops training after the current epoch, and the model trained so far is saved
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	err = train(ctx, dataSet, flag.Arg(1))
 	if err != nil {
-		fmt.Printf("Error in training: %s ", err)
-		return
+		fmt.Printf("%s \n", err)
+	}
+
+}
+
+//train trains a model on the dataset until done or until the context is cancelled,
+//and saves the model trained so far to the output file
+func train(ctx context.Context, dataSet []ml.Example, outputFileName string) error {
+
+	model, _, err := ml.TrainContext(ctx, dataSet, ml.TrainOptions{LearningRate: learningRate, NumEpochs: numEpochs,
+		EpochListener: ml.PrintEpochStats})
+	if err != nil {
+		return fmt.Errorf("error in training: %w", err)
+	}
+	if ctx.Err() != nil {
+		fmt.Printf("Training interrupted, saving the model trained so far to %s\n", outputFileName)
 	}
-	err = ml.SaveModel(model, os.Args[2])
+
+	err = ml.SaveModel(model, outputFileName)
 	if err != nil {
-		fmt.Printf("Error saving model: %s \n", err)
+		return fmt.Errorf("error saving model: %w", err)
+	}
+	return nil
+}
+
+//readDataSet reads the training dataset from the provided file, or from stdin if the file name is -
+func readDataSet(fileName string) ([]ml.Example, ml.LoadStats, error) {
+	if fileName != "-" {
+		return ml.ReadCSVDataSetWithOptions(fileName, ml.DefaultCSVOptions())
+	}
+	return ml.ReadCSVDataSetFrom(os.Stdin, ml.DefaultCSVOptions())
+}
+
+//printSummary writes the dataset summary and the training configuration
+func printSummary(w io.Writer, dataSet []ml.Example, stats ml.LoadStats) {
+
+	fmt.Fprintf(w, "Dropped %d rows with an invalid feature count and %d rows with an invalid label\n",
+		stats.DroppedFeatureCount, stats.DroppedInvalidLabel)
+
+	summary := ml.Summarize(dataSet)
+	fmt.Fprintf(w, "Features: %d\n", summary.NumFeatures)
+	fmt.Fprintf(w, "Labels: min %.03f max %.03f mean %.03f\n", summary.MinLabel, summary.MaxLabel, summary.MeanLabel)
+
+	labels := make([]float64, 0, len(summary.LabelCounts))
+	for label := range summary.LabelCounts {
+		labels = append(labels, label)
+	}
+	sort.Float64s(labels)
+	for _, label := range labels {
+		fmt.Fprintf(w, "  %.03f: %d\n", label, summary.LabelCounts[label])
 	}
 
+	fmt.Fprintf(w, "Learning rate: %g\nEpochs: %d\n", learningRate, numEpochs)
 }
 
 func printDataSet(dataSet []ml.Example) {
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jjviana/ml4devs/pkg/ml"
+)
+
+func TestPrintSummary(t *testing.T) {
+
+	dataSet := []ml.Example{{Features: []float64{1, 2}, Label: 5}, {Features: []float64{3, 4}, Label: 6},
+		{Features: []float64{5, 6}, Label: 5}}
+	stats := ml.LoadStats{Examples: 3, DroppedFeatureCount: 1, DroppedInvalidLabel: 2}
+
+	var output bytes.Buffer
+	printSummary(&output, dataSet, stats)
+
+	for _, expected := range []string{
+		"Dropped 1 rows with an invalid feature count and 2 rows with an invalid label",
+		"Features: 2",
+		"Labels: min 5.000 max 6.000 mean 5.333",
+		"  5.000: 2\n  6.000: 1",
+		"Learning rate: 0.001\nEpochs: 100",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Fatalf("expected the summary to contain %q, found:\n%s", expected, output.String())
+		}
+	}
+}
+
+func TestTrainSavesModelWhenCancelled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	outputFileName := filepath.Join(t.TempDir(), "wine.model")
+
+	if err := train(ctx, ml.GenerateSyntheticDataset(50, 3, 0.5, 1), outputFileName); err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+
+	model, err := ml.LoadModel(outputFileName)
+	if err != nil {
+		t.Fatalf("error loading the saved model: %v", err)
+	}
+	//Training stops after the epoch running when the context is cancelled
+	expected, epochs, err := ml.TrainWithOptions(ml.GenerateSyntheticDataset(50, 3, 0.5, 1),
+		ml.TrainOptions{LearningRate: learningRate, NumEpochs: 1})
+	if err != nil || epochs != 1 {
+		t.Fatalf("error training: %v", err)
+	}
+	if model.Bias != expected.Bias || len(model.Coeficients) != 3 || model.Coeficients[0] != expected.Coeficients[0] {
+		t.Fatalf("expected the model trained for a single epoch %v, found %v", expected, model)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/jjviana/ml4devs
 
-go 1.14
+go 1.16
@@ -0,0 +1,211 @@
+package ml
+
+import (
+	"math"
+	"sort"
+)
+
+//Models trained with a classification loss (CrossEntropy or FocalLoss) predict the logit of the positive class,
+//and labels above 0.5 are positive. The functions below evaluate such models on their binary decisions.
+
+//DefaultThreshold is the probability from which an example is predicted positive, unless another threshold is chosen
+const DefaultThreshold = 0.5
+
+//Probability returns the probability of the positive class (the sigmoid of the prediction)
+//for an example with normalized features, given a model trained with a classification loss
+func Probability(model Model, example Example) float64 {
+	return sigmoid(Predict(model, example))
+}
+
+//DecisionThreshold returns the threshold of the model, or DefaultThreshold if it is not set
+func (m Model) DecisionThreshold() float64 {
+	if m.Threshold == 0 {
+		return DefaultThreshold
+	}
+	return m.Threshold
+}
+
+//PredictClass returns 1 if the probability of the positive class is at least the model threshold
+//(see DecisionThreshold), and 0 otherwise. As with Predict, the example features must be normalized.
+func PredictClass(model Model, example Example) float64 {
+	if Probability(model, example) >= model.DecisionThreshold() {
+		return 1
+	}
+	return 0
+}
+
+//BinaryConfusionMatrix counts the decisions of a model trained with a classification loss
+type BinaryConfusionMatrix struct {
+	TruePositives  int
+	FalsePositives int
+	TrueNegatives  int
+	FalseNegatives int
+}
+
+//NewBinaryConfusionMatrix builds the confusion matrix of the decisions of the model (see PredictClass) on the dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+func NewBinaryConfusionMatrix(model Model, data []Example) BinaryConfusionMatrix {
+
+	matrix := BinaryConfusionMatrix{}
+	for _, example := range data {
+		predictedPositive := PredictClass(model, normalizeExample(model, example)) == 1
+		positive := example.Label > 0.5
+		switch {
+		case predictedPositive && positive:
+			matrix.TruePositives++
+		case predictedPositive:
+			matrix.FalsePositives++
+		case positive:
+			matrix.FalseNegatives++
+		default:
+			matrix.TrueNegatives++
+		}
+	}
+	return matrix
+}
+
+//ExpectedCost returns the average cost of the decisions of a model trained with a classification loss, when
+//examples with a probability (see Probability) of at least threshold are predicted positive.
+//Every false positive costs fpCost and every false negative fnCost.
+//The dataset features must not be normalized, and the dataset is not modified.
+func ExpectedCost(model Model, data []Example, threshold, fpCost, fnCost float64) float64 {
+
+	sumCost := 0.0
+	for _, example := range data {
+		predictedPositive := Probability(model, normalizeExample(model, example)) >= threshold
+		positive := example.Label > 0.5
+		if predictedPositive && !positive {
+			sumCost += fpCost
+		} else if !predictedPositive && positive {
+			sumCost += fnCost
+		}
+	}
+	return sumCost / float64(len(data))
+}
+
+//BestThresholdByCost returns the threshold minimizing ExpectedCost, and the resulting cost.
+//The candidate thresholds are the probabilities of the examples, and one above all of them
+//(predicting every example negative). Ties are broken in favour of the highest threshold.
+//Set Model.Threshold to the result to save it with the model.
+func BestThresholdByCost(model Model, data []Example, fpCost, fnCost float64) (float64, float64) {
+
+	sweep := sweepThresholds(model, data)
+	if len(sweep.thresholds) == 0 {
+		return DefaultThreshold, math.NaN()
+	}
+
+	//Start predicting every example negative
+	bestThreshold := math.Nextafter(sweep.thresholds[0], math.Inf(1))
+	bestCost := float64(sweep.positives) * fnCost
+	for i, threshold := range sweep.thresholds {
+		cost := float64(sweep.falsePositives[i])*fpCost + float64(sweep.positives-sweep.truePositives[i])*fnCost
+		if cost < bestCost {
+			bestThreshold, bestCost = threshold, cost
+		}
+	}
+	return bestThreshold, bestCost / float64(len(data))
+}
+
+//BestThreshold returns the threshold maximizing the F1 score of the positive class, and that score.
+//The candidate thresholds are the probabilities of the examples, ties are broken in favour of the highest threshold.
+//Set Model.Threshold to the result to save it with the model.
+func BestThreshold(model Model, data []Example) (float64, float64) {
+
+	sweep := sweepThresholds(model, data)
+	bestThreshold, bestF1 := DefaultThreshold, 0.0
+	for i, threshold := range sweep.thresholds {
+		truePositives := sweep.truePositives[i]
+		score := f1(ratio(truePositives, truePositives+sweep.falsePositives[i]), ratio(truePositives, sweep.positives))
+		if score > bestF1 {
+			bestThreshold, bestF1 = threshold, score
+		}
+	}
+	return bestThreshold, bestF1
+}
+
+//thresholdSweep counts the outcome of every distinct decision threshold on a dataset
+type thresholdSweep struct {
+	//thresholds are the distinct probabilities of the examples, in decreasing order
+	thresholds []float64
+	//truePositives[i] and falsePositives[i] count the examples predicted positive with thresholds[i]
+	truePositives  []int
+	falsePositives []int
+	//positives is the number of positive examples
+	positives int
+}
+
+//sweepThresholds computes the outcome of every distinct decision threshold on a dataset
+//whose features are not normalized
+func sweepThresholds(model Model, data []Example) thresholdSweep {
+
+	probabilities := make([]float64, len(data))
+	order := make([]int, len(data))
+	for i, example := range data {
+		probabilities[i] = Probability(model, normalizeExample(model, example))
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return probabilities[order[a]] > probabilities[order[b]]
+	})
+
+	sweep := thresholdSweep{}
+	truePositives, falsePositives := 0, 0
+	for k, i := range order {
+		if data[i].Label > 0.5 {
+			truePositives++
+			sweep.positives++
+		} else {
+			falsePositives++
+		}
+		//Examples with the same probability are on the same side of every threshold
+		if k+1 < len(order) && probabilities[order[k+1]] == probabilities[i] {
+			continue
+		}
+		sweep.thresholds = append(sweep.thresholds, probabilities[i])
+		sweep.truePositives = append(sweep.truePositives, truePositives)
+		sweep.falsePositives = append(sweep.falsePositives, falsePositives)
+	}
+	return sweep
+}
+
+//PRPoint is a point of a precision-recall curve
+type PRPoint struct {
+	Threshold float64
+	Precision float64
+	Recall    float64
+}
+
+//PRCurve returns the precision and recall of the positive class for every distinct probability of the examples
+//used as threshold, by decreasing threshold (so increasing recall).
+//The dataset features must not be normalized, and the dataset is not modified.
+func PRCurve(model Model, data []Example) []PRPoint {
+
+	sweep := sweepThresholds(model, data)
+	curve := make([]PRPoint, len(sweep.thresholds))
+	for i, threshold := range sweep.thresholds {
+		truePositives := sweep.truePositives[i]
+		curve[i] = PRPoint{Threshold: threshold,
+			Precision: ratio(truePositives, truePositives+sweep.falsePositives[i]),
+			Recall:    ratio(truePositives, sweep.positives)}
+	}
+	return curve
+}
+
+//PRAUC returns the area under the precision-recall curve (see PRCurve), computed with the trapezoidal rule.
+//The curve starts at recall 0 with the precision of its first point. A model that can not tell the classes
+//apart scores the fraction of positive examples, a perfect model scores 1.
+//Unlike accuracy or ROC AUC, it is not inflated by a large number of easy negative examples.
+func PRAUC(model Model, data []Example) float64 {
+
+	curve := PRCurve(model, data)
+	if len(curve) == 0 {
+		return 0
+	}
+	area := 0.0
+	previous := PRPoint{Precision: curve[0].Precision}
+	for _, point := range curve {
+		area += (point.Recall - previous.Recall) * (point.Precision + previous.Precision) / 2
+		previous = point
+	}
+	return area
+}
@@ -0,0 +1,139 @@
+package ml
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+//logitModel returns a model whose prediction (the logit) is the single feature of an example
+func logitModel() Model {
+	return Model{Coeficients: []float64{1}, MinFeatureValues: []float64{0}, MaxFeatureValues: []float64{1}}
+}
+
+func TestBestThresholdByCost(t *testing.T) {
+
+	//Logits and labels: one positive has the lowest logit, and one negative the highest
+	data := []Example{example(1, -3), example(0, -2), example(0, -1), example(0, 0),
+		example(1, 1), example(1, 2), example(1, 3), example(0, 4)}
+	model := logitModel()
+
+	//From the logit 1: 3 true positives, 1 false positive and 1 false negative
+	threshold, score := BestThreshold(model, data)
+	if threshold != sigmoid(1) || score != 0.75 {
+		t.Fatalf("expected the F1 optimal threshold %v with F1 0.75, found %v with %v", sigmoid(1), threshold, score)
+	}
+	if cost := ExpectedCost(model, data, threshold, 1, 5); cost != 6.0/8 {
+		t.Fatalf("expected cost %v, found %v", 6.0/8, cost)
+	}
+
+	//A false negative costs 5 times a false positive: better predict everything positive
+	threshold, cost := BestThresholdByCost(model, data, 1, 5)
+	if threshold != sigmoid(-3) || cost != 4.0/8 {
+		t.Fatalf("expected the cost optimal threshold %v with cost 0.5, found %v with %v", sigmoid(-3), threshold, cost)
+	}
+	if expected := ExpectedCost(model, data, threshold, 1, 5); expected != cost {
+		t.Fatalf("expected the best cost to match ExpectedCost %v, found %v", expected, cost)
+	}
+
+	//A false positive costs 5 times a false negative: better predict everything negative
+	threshold, cost = BestThresholdByCost(model, data, 5, 1)
+	if threshold <= sigmoid(4) || cost != 4.0/8 || ExpectedCost(model, data, threshold, 5, 1) != cost {
+		t.Fatalf("expected a threshold above %v with cost 0.5, found %v with %v", sigmoid(4), threshold, cost)
+	}
+}
+
+func TestBestThresholdOfTrainedClassifier(t *testing.T) {
+
+	data := classificationDataset(500, 10, 0, 0.1, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.05, NumEpochs: 20, Loss: CrossEntropy{}})
+
+	for _, threshold := range []float64{0.1, DefaultThreshold, 0.9} {
+		if _, cost := BestThresholdByCost(model, data, 1, 5); cost > ExpectedCost(model, data, threshold, 1, 5) {
+			t.Fatalf("expected the best cost %v to be at most the cost %v of threshold %v",
+				cost, ExpectedCost(model, data, threshold, 1, 5), threshold)
+		}
+	}
+	if _, score := BestThreshold(model, data); score < 0.8 {
+		t.Fatalf("expected a high F1 score, found %v", score)
+	}
+}
+
+func TestThresholdIsSavedWithTheModel(t *testing.T) {
+
+	data := []Example{example(0, -1), example(1, 0.5), example(1, 1), example(0, 2)}
+	model := logitModel()
+	if matrix := NewBinaryConfusionMatrix(model, data); matrix != (BinaryConfusionMatrix{TruePositives: 2, FalsePositives: 1, TrueNegatives: 1}) {
+		t.Fatalf("expected the default threshold to predict 3 positives, found %+v", matrix)
+	}
+
+	//Only the logit 2 is predicted positive
+	model.Threshold = sigmoid(1.5)
+	var buffer bytes.Buffer
+	if err := SaveModelTo(&buffer, model); err != nil {
+		t.Fatalf("error saving model: %v", err)
+	}
+	loaded, err := LoadModelFrom(&buffer)
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+	if loaded.Threshold != model.Threshold || loaded.Fingerprint != model.ComputeFingerprint() {
+		t.Fatalf("expected threshold %v, found %v", model.Threshold, loaded.Threshold)
+	}
+	if matrix := NewBinaryConfusionMatrix(loaded, data); matrix != (BinaryConfusionMatrix{FalsePositives: 1, TrueNegatives: 1, FalseNegatives: 2}) {
+		t.Fatalf("expected the saved threshold to predict a single positive, found %+v", matrix)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "model.txt")
+	if err := SaveModelText(model, fileName); err != nil {
+		t.Fatalf("error saving model: %v", err)
+	}
+	if loaded, err = LoadModelText(fileName); err != nil || loaded.Threshold != model.Threshold {
+		t.Fatalf("expected threshold %v, found %v (%v)", model.Threshold, loaded.Threshold, err)
+	}
+
+	if model.ComputeFingerprint() == logitModel().ComputeFingerprint() || logitModel().DecisionThreshold() != DefaultThreshold {
+		t.Fatalf("expected the threshold to change the fingerprint, and the default threshold to be used if not set")
+	}
+}
+
+func TestPRCurve(t *testing.T) {
+
+	data := []Example{example(1, 3), example(0, 2), example(1, 1), example(0, 0)}
+
+	curve := PRCurve(logitModel(), data)
+	expected := []PRPoint{{sigmoid(3), 1, 0.5}, {sigmoid(2), 0.5, 0.5}, {sigmoid(1), 2.0 / 3, 1}, {sigmoid(0), 0.5, 1}}
+	if len(curve) != len(expected) {
+		t.Fatalf("expected %v, found %v", expected, curve)
+	}
+	for i := range expected {
+		if curve[i] != expected[i] {
+			t.Fatalf("expected %v, found %v", expected, curve)
+		}
+	}
+
+	//0.5*1 from recall 0 to 0.5, then 0.5*(0.5+2/3)/2
+	expectedAUC := 0.5 + 0.5*(0.5+2.0/3)/2
+	if auc := PRAUC(logitModel(), data); math.Abs(auc-expectedAUC) > 1e-12 {
+		t.Fatalf("expected PR AUC %v, found %v", expectedAUC, auc)
+	}
+}
+
+func TestPRAUCOnImbalancedData(t *testing.T) {
+
+	//About 8% of positives
+	data := classificationDataset(2000, 16, 0, 0.01, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.05, NumEpochs: 50, Loss: CrossEntropy{}})
+	prevalence := float64(Summarize(data).LabelCounts[1]) / float64(len(data))
+
+	if auc := PRAUC(model, data); auc < 0.8 {
+		t.Fatalf("expected a high PR AUC for a trained model, found %v", auc)
+	}
+
+	//Always predicts the negative majority class
+	majority := Model{Bias: -5, Coeficients: []float64{0, 0}, MinFeatureValues: []float64{0, 0}, MaxFeatureValues: []float64{1, 1}}
+	if auc := PRAUC(majority, data); math.Abs(auc-prevalence) > 1e-12 {
+		t.Fatalf("expected the PR AUC of the majority classifier to be the positive prevalence %v, found %v", prevalence, auc)
+	}
+}
@@ -0,0 +1,104 @@
+package ml
+
+import (
+	"math"
+	"sort"
+)
+
+//MultiConfusionMatrix counts predictions per actual and predicted class.
+//Labels are treated as classes, and predictions are rounded to the nearest integer.
+type MultiConfusionMatrix struct {
+	//Classes are all the actual and predicted classes, in increasing order
+	Classes []float64
+	//Counts[i][j] is the number of examples of class Classes[i] predicted as Classes[j]
+	Counts [][]int
+}
+
+//NewMultiConfusionMatrix builds the confusion matrix of the model on the dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+func NewMultiConfusionMatrix(model Model, data []Example) MultiConfusionMatrix {
+
+	predictions := make([]float64, len(data))
+	classIndex := make(map[float64]int)
+	for i, example := range data {
+		predictions[i] = math.Round(Predict(model, normalizeExample(model, example)))
+		classIndex[example.Label] = 0
+		classIndex[predictions[i]] = 0
+	}
+
+	matrix := MultiConfusionMatrix{Classes: make([]float64, 0, len(classIndex))}
+	for class := range classIndex {
+		matrix.Classes = append(matrix.Classes, class)
+	}
+	sort.Float64s(matrix.Classes)
+	matrix.Counts = make([][]int, len(matrix.Classes))
+	for i, class := range matrix.Classes {
+		classIndex[class] = i
+		matrix.Counts[i] = make([]int, len(matrix.Classes))
+	}
+
+	for i, example := range data {
+		matrix.Counts[classIndex[example.Label]][classIndex[predictions[i]]]++
+	}
+
+	return matrix
+}
+
+//PrecisionRecall returns the precision and recall of the class at index i of Classes
+func (m MultiConfusionMatrix) PrecisionRecall(i int) (precision, recall float64) {
+
+	truePositives, predicted, actual := m.classCounts(i)
+	return ratio(truePositives, predicted), ratio(truePositives, actual)
+}
+
+//MacroF1 returns the average of the per-class F1 scores.
+//Every class has the same weight, regardless of how many examples it has.
+func (m MultiConfusionMatrix) MacroF1() float64 {
+
+	if len(m.Classes) == 0 {
+		return 0
+	}
+	sumF1 := 0.0
+	for i := range m.Classes {
+		sumF1 += f1(m.PrecisionRecall(i))
+	}
+	return sumF1 / float64(len(m.Classes))
+}
+
+//MicroF1 returns the F1 score computed from the true positives, false positives
+//and false negatives summed over all classes, so classes are weighted by their number of examples.
+//With a single label per example it is equal to the accuracy.
+func (m MultiConfusionMatrix) MicroF1() float64 {
+
+	sumTruePositives, sumPredicted, sumActual := 0, 0, 0
+	for i := range m.Classes {
+		truePositives, predicted, actual := m.classCounts(i)
+		sumTruePositives += truePositives
+		sumPredicted += predicted
+		sumActual += actual
+	}
+	return f1(ratio(sumTruePositives, sumPredicted), ratio(sumTruePositives, sumActual))
+}
+
+//classCounts returns the true positives, predicted and actual examples of the class at index i
+func (m MultiConfusionMatrix) classCounts(i int) (truePositives, predicted, actual int) {
+	for j := range m.Classes {
+		predicted += m.Counts[j][i]
+		actual += m.Counts[i][j]
+	}
+	return m.Counts[i][i], predicted, actual
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func f1(precision, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
@@ -0,0 +1,42 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMultiConfusionMatrix(t *testing.T) {
+
+	//The model predicts the feature value
+	model := Model{Coeficients: []float64{1}, MinFeatureValues: []float64{0}, MaxFeatureValues: []float64{1}}
+	pairs := [][2]float64{
+		{0, 0}, {0, 0}, {0, 0}, {0, 1},
+		{1, 1}, {1, 2},
+		{2, 2}, {2, 2}, {2, 2}, {2, 2},
+	}
+	data := make([]Example, len(pairs))
+	for i, pair := range pairs {
+		data[i] = example(pair[0], pair[1])
+	}
+
+	matrix := NewMultiConfusionMatrix(model, data)
+
+	expected := [][]int{{3, 1, 0}, {0, 1, 1}, {0, 0, 4}}
+	for i := range expected {
+		if !equalInts(matrix.Counts[i], expected[i]) {
+			t.Fatalf("expected counts %v, found %v", expected, matrix.Counts)
+		}
+	}
+	if precision, recall := matrix.PrecisionRecall(2); precision != 0.8 || recall != 1 {
+		t.Fatalf("expected precision 0.8 and recall 1 for class 2, found %v and %v", precision, recall)
+	}
+
+	//Per class F1: 6/7, 1/2 and 8/9
+	if macro := matrix.MacroF1(); math.Abs(macro-(6.0/7+0.5+8.0/9)/3) > 1e-12 {
+		t.Fatalf("expected macro F1 %v, found %v", (6.0/7+0.5+8.0/9)/3, macro)
+	}
+	//Equal to the accuracy
+	if micro := matrix.MicroF1(); math.Abs(micro-0.8) > 1e-12 {
+		t.Fatalf("expected micro F1 0.8, found %v", micro)
+	}
+}
@@ -0,0 +1,275 @@
+package ml
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+//Dedup removes exact duplicate examples (same features and label) from the dataset,
+//keeping the first occurrence of each.
+//Returns the deduplicated dataset and the number of examples removed
+func Dedup(data []Example) ([]Example, int) {
+
+	seen := make(map[string]bool, len(data))
+	result := make([]Example, 0, len(data))
+
+	for _, example := range data {
+		key := exampleKey(example, true)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, example)
+	}
+
+	return result, len(data) - len(result)
+}
+
+//exampleKey builds a key identifying the example features (and label, if requested)
+func exampleKey(example Example, withLabel bool) string {
+
+	key := make([]byte, 8*(len(example.Features)+1))
+	for i, feature := range example.Features {
+		binary.LittleEndian.PutUint64(key[8*i:], math.Float64bits(feature))
+	}
+	if !withLabel {
+		return string(key[:8*len(example.Features)])
+	}
+	binary.LittleEndian.PutUint64(key[8*len(example.Features):], math.Float64bits(example.Label))
+	return string(key)
+}
+
+//Overlap returns the indices of the test examples that also appear (same features and label)
+//in the training set. Both datasets must be in the same state (normalized or not).
+func Overlap(train, test []Example) []int {
+	return overlap(train, test, true)
+}
+
+//OverlapFeatures returns the indices of the test examples whose features also appear
+//in the training set, regardless of their label
+func OverlapFeatures(train, test []Example) []int {
+	return overlap(train, test, false)
+}
+
+func overlap(train, test []Example, withLabel bool) []int {
+
+	trainKeys := make(map[string]bool, len(train))
+	for _, example := range train {
+		trainKeys[exampleKey(example, withLabel)] = true
+	}
+
+	result := make([]int, 0)
+	for i, example := range test {
+		if trainKeys[exampleKey(example, withLabel)] {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+//AddPairFeatures returns the features followed by the product of every unordered pair of features,
+//allowing a linear model to capture interactions between them.
+//For n features, n*(n-1)/2 features are added.
+func AddPairFeatures(features []float64) []float64 {
+
+	result := make([]float64, len(features), len(features)+len(features)*(len(features)-1)/2)
+	copy(result, features)
+	for i := 0; i < len(features); i++ {
+		for j := i + 1; j < len(features); j++ {
+			result = append(result, features[i]*features[j])
+		}
+	}
+	return result
+}
+
+//DataSetSummary describes the contents of a dataset
+type DataSetSummary struct {
+	Examples    int
+	NumFeatures int
+	//LabelCounts is the number of examples with each label value
+	LabelCounts map[float64]int
+	MinLabel    float64
+	MaxLabel    float64
+	MeanLabel   float64
+}
+
+//Summarize describes the dataset, without modifying it
+func Summarize(data []Example) DataSetSummary {
+
+	summary := DataSetSummary{Examples: len(data), LabelCounts: make(map[float64]int)}
+	if len(data) == 0 {
+		return summary
+	}
+
+	summary.NumFeatures = len(data[0].Features)
+	summary.MinLabel = math.MaxFloat64
+	summary.MaxLabel = -math.MaxFloat64
+	sumLabels := 0.0
+	for _, example := range data {
+		summary.LabelCounts[example.Label]++
+		summary.MinLabel = math.Min(summary.MinLabel, example.Label)
+		summary.MaxLabel = math.Max(summary.MaxLabel, example.Label)
+		sumLabels += example.Label
+	}
+	summary.MeanLabel = sumLabels / float64(len(data))
+
+	return summary
+}
+
+//FeatureCoverage returns the fraction of test feature values that fall within the range
+//of values seen for the same feature in the training set.
+//Low coverage means the model is extrapolating, a sign of domain shift.
+//Both datasets must be in the same state (normalized or not).
+func FeatureCoverage(train, test []Example) float64 {
+
+	if len(train) == 0 || len(test) == 0 {
+		return 0
+	}
+
+	numFeatures := len(train[0].Features)
+	minValues := make([]float64, numFeatures)
+	maxValues := make([]float64, numFeatures)
+	for j := 0; j < numFeatures; j++ {
+		minValues[j] = math.MaxFloat64
+		maxValues[j] = -math.MaxFloat64
+	}
+	for _, example := range train {
+		for j := 0; j < numFeatures && j < len(example.Features); j++ {
+			minValues[j] = math.Min(minValues[j], example.Features[j])
+			maxValues[j] = math.Max(maxValues[j], example.Features[j])
+		}
+	}
+
+	covered, total := 0, 0
+	for _, example := range test {
+		for j, value := range example.Features {
+			total++
+			if j < numFeatures && value >= minValues[j] && value <= maxValues[j] {
+				covered++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	return float64(covered) / float64(total)
+}
+
+//ConstantFeatures returns the indices of the features that have the same value in every example.
+//They carry no information, and break normalization (their minimum and maximum values are equal).
+//All examples must have the same number of features.
+func ConstantFeatures(data []Example) ([]int, error) {
+
+	constant := make([]int, 0)
+	if len(data) == 0 {
+		return constant, nil
+	}
+	for i, example := range data {
+		if len(example.Features) != len(data[0].Features) {
+			return nil, fmt.Errorf("%w: example %d: expected %d features, found %d",
+				ErrFeatureCountMismatch, i, len(data[0].Features), len(example.Features))
+		}
+	}
+	for j, value := range data[0].Features {
+		isConstant := true
+		for _, example := range data[1:] {
+			if example.Features[j] != value {
+				isConstant = false
+				break
+			}
+		}
+		if isConstant {
+			constant = append(constant, j)
+		}
+	}
+	return constant, nil
+}
+
+//DropFeatures returns a copy of the dataset without the provided features.
+//The same features must be dropped from any dataset later used with a model trained on the result.
+func DropFeatures(data []Example, features []int) []Example {
+
+	dropped := make(map[int]bool, len(features))
+	for _, feature := range features {
+		dropped[feature] = true
+	}
+
+	result := make([]Example, len(data))
+	for i, example := range data {
+		result[i] = example
+		result[i].Features = make([]float64, 0, len(example.Features))
+		for j, value := range example.Features {
+			if !dropped[j] {
+				result[i].Features = append(result[i].Features, value)
+			}
+		}
+	}
+	return result
+}
+
+//Fold is a cross-validation split, holding indices into the dataset
+type Fold struct {
+	Train      []int
+	Validation []int
+}
+
+//GroupKFold splits the dataset into k folds for cross-validation, keeping all the examples
+//of a group (groups[i] is the group of data[i]) in the same fold, so related examples
+//can't be in both the training and the validation set.
+//Groups are assigned, largest first, to the fold with the fewest examples so far.
+//Note that Train normalizes features in place: copy the examples of each fold before training on it.
+func GroupKFold(data []Example, groups []int, k int) ([]Fold, error) {
+
+	if len(groups) != len(data) {
+		return nil, fmt.Errorf("expected %d groups, found %d", len(data), len(groups))
+	}
+
+	groupExamples := make(map[int][]int)
+	for i, group := range groups {
+		groupExamples[group] = append(groupExamples[group], i)
+	}
+	if k < 2 || len(groupExamples) < k {
+		return nil, fmt.Errorf("can not split %d groups into %d folds", len(groupExamples), k)
+	}
+
+	sortedGroups := make([]int, 0, len(groupExamples))
+	for group := range groupExamples {
+		sortedGroups = append(sortedGroups, group)
+	}
+	sort.Slice(sortedGroups, func(i, j int) bool {
+		a, b := sortedGroups[i], sortedGroups[j]
+		if len(groupExamples[a]) != len(groupExamples[b]) {
+			return len(groupExamples[a]) > len(groupExamples[b])
+		}
+		return a < b
+	})
+
+	validation := make([][]int, k)
+	for _, group := range sortedGroups {
+		smallest := 0
+		for fold := range validation {
+			if len(validation[fold]) < len(validation[smallest]) {
+				smallest = fold
+			}
+		}
+		validation[smallest] = append(validation[smallest], groupExamples[group]...)
+	}
+
+	folds := make([]Fold, k)
+	for fold := range folds {
+		sort.Ints(validation[fold])
+		folds[fold].Validation = validation[fold]
+		folds[fold].Train = make([]int, 0, len(data)-len(validation[fold]))
+		for other := range folds {
+			if other != fold {
+				folds[fold].Train = append(folds[fold].Train, validation[other]...)
+			}
+		}
+		sort.Ints(folds[fold].Train)
+	}
+
+	return folds, nil
+}
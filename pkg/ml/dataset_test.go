@@ -0,0 +1,154 @@
+package ml
+
+import (
+	"errors"
+	"testing"
+)
+
+//example returns an example with the provided label and features
+func example(label float64, features ...float64) Example {
+	return Example{Features: features, Label: label}
+}
+
+//equalInts tells whether two int slices have the same values
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDedup(t *testing.T) {
+
+	data := []Example{example(1, 1, 2), example(2, 3, 4), example(1, 1, 2), example(2, 1, 2),
+		example(2, 3, 4), example(3, 5, 6)}
+
+	deduped, removed := Dedup(data)
+	if removed != 2 {
+		t.Fatalf("expected 2 duplicates removed, found %d", removed)
+	}
+	expected := []Example{example(1, 1, 2), example(2, 3, 4), example(2, 1, 2), example(3, 5, 6)}
+	if len(deduped) != len(expected) {
+		t.Fatalf("expected %d examples, found %d", len(expected), len(deduped))
+	}
+	for i := range expected {
+		if deduped[i].Label != expected[i].Label || !equalFloats(deduped[i].Features, expected[i].Features) {
+			t.Fatalf("example %d: expected %v, found %v", i, expected[i], deduped[i])
+		}
+	}
+}
+
+func TestOverlap(t *testing.T) {
+
+	train := []Example{example(1, 1, 2), example(2, 3, 4), example(3, 5, 6)}
+	test := []Example{example(1, 1, 2), example(9, 9, 9), example(7, 3, 4), example(3, 5, 6)}
+
+	if overlap := Overlap(train, test); !equalInts(overlap, []int{0, 3}) {
+		t.Fatalf("expected overlap [0 3], found %v", overlap)
+	}
+	if overlap := OverlapFeatures(train, test); !equalInts(overlap, []int{0, 2, 3}) {
+		t.Fatalf("expected feature overlap [0 2 3], found %v", overlap)
+	}
+}
+
+func TestAddPairFeatures(t *testing.T) {
+
+	features := AddPairFeatures([]float64{2, 3, 5})
+	expected := []float64{2, 3, 5, 6, 10, 15}
+	if !equalFloats(features, expected) {
+		t.Fatalf("expected %v, found %v", expected, features)
+	}
+}
+
+func TestFeatureCoverage(t *testing.T) {
+
+	train := []Example{example(1, 0, 10), example(2, 5, 20)}
+
+	if coverage := FeatureCoverage(train, []Example{example(1, -1, 30), example(2, 6, 5)}); coverage != 0 {
+		t.Fatalf("expected no coverage, found %v", coverage)
+	}
+	if coverage := FeatureCoverage(train, []Example{example(1, 0, 10), example(2, 2.5, 15)}); coverage != 1 {
+		t.Fatalf("expected full coverage, found %v", coverage)
+	}
+	if coverage := FeatureCoverage(train, []Example{example(1, 1, 30)}); coverage != 0.5 {
+		t.Fatalf("expected half coverage, found %v", coverage)
+	}
+}
+
+func TestConstantAndDropFeatures(t *testing.T) {
+
+	data := []Example{example(1, 7, 1, 0, 3), example(2, 7, 2, 0, 3), example(3, 7, 3, 0, 4)}
+
+	constant, err := ConstantFeatures(data)
+	if err != nil || !equalInts(constant, []int{0, 2}) {
+		t.Fatalf("expected constant features [0 2], found %v (%v)", constant, err)
+	}
+
+	dropped := DropFeatures(data, constant)
+	for i, expected := range [][]float64{{1, 3}, {2, 3}, {3, 4}} {
+		if !equalFloats(dropped[i].Features, expected) || dropped[i].Label != data[i].Label {
+			t.Fatalf("example %d: expected features %v, found %v", i, expected, dropped[i])
+		}
+	}
+	if len(data[0].Features) != 4 {
+		t.Fatalf("expected the original dataset to be unchanged")
+	}
+	if constant, err := ConstantFeatures(dropped); err != nil || len(constant) != 0 {
+		t.Fatalf("expected no constant features left, found %v (%v)", constant, err)
+	}
+
+	if _, err := ConstantFeatures([]Example{example(1, 1, 2), example(2, 1)}); !errors.Is(err, ErrFeatureCountMismatch) {
+		t.Fatalf("expected ErrFeatureCountMismatch for a shorter example, found %v", err)
+	}
+}
+
+func TestGroupKFold(t *testing.T) {
+
+	data := GenerateSyntheticDataset(30, 2, 0, 1)
+	groups := make([]int, len(data))
+	for i := range groups {
+		//Groups of different sizes
+		groups[i] = i * i % 7
+	}
+
+	folds, err := GroupKFold(data, groups, 3)
+	if err != nil {
+		t.Fatalf("error splitting folds: %v", err)
+	}
+	validated := make(map[int]int)
+	for f, fold := range folds {
+		if len(fold.Train)+len(fold.Validation) != len(data) {
+			t.Fatalf("fold %d: expected %d examples, found %d", f, len(data), len(fold.Train)+len(fold.Validation))
+		}
+		validationGroups := make(map[int]bool)
+		for _, i := range fold.Validation {
+			validationGroups[groups[i]] = true
+			validated[i]++
+		}
+		for _, i := range fold.Train {
+			if validationGroups[groups[i]] {
+				t.Fatalf("fold %d: group %d split across training and validation", f, groups[i])
+			}
+		}
+	}
+	if len(validated) != len(data) {
+		t.Fatalf("expected every example to be validated once, found %d", len(validated))
+	}
+	for i, count := range validated {
+		if count != 1 {
+			t.Fatalf("example %d validated %d times", i, count)
+		}
+	}
+
+	if _, err := GroupKFold(data, groups[:10], 3); err == nil {
+		t.Fatalf("expected an error for a group count mismatch")
+	}
+	if _, err := GroupKFold(data, make([]int, len(data)), 2); err == nil {
+		t.Fatalf("expected an error for fewer groups than folds")
+	}
+}
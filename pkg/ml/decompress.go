@@ -0,0 +1,46 @@
+package ml
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+//Decompressor wraps a compressed stream, returning a reader for the decompressed contents.
+//It allows callers to plug in formats (such as zstd) the package does not support natively.
+type Decompressor func(io.Reader) (io.Reader, error)
+
+//NoDecompressor returns the input unchanged
+func NoDecompressor(r io.Reader) (io.Reader, error) {
+	return r, nil
+}
+
+//GzipDecompressor decompresses gzip streams
+func GzipDecompressor(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+//Bzip2Decompressor decompresses bzip2 streams
+func Bzip2Decompressor(r io.Reader) (io.Reader, error) {
+	return bzip2.NewReader(r), nil
+}
+
+//DecompressorForFile selects a decompressor based on the file extension.
+//Files with unknown extensions are read uncompressed.
+//Formats without a built-in decompressor (.zst) return an error: callers must
+//provide one through CSVOptions.Decompressor.
+func DecompressorForFile(fileName string) (Decompressor, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".gz":
+		return GzipDecompressor, nil
+	case ".bz2":
+		return Bzip2Decompressor, nil
+	case ".zst":
+		return nil, fmt.Errorf("no built-in decompressor for %s, please provide one", fileName)
+	default:
+		return NoDecompressor, nil
+	}
+}
@@ -0,0 +1,47 @@
+package ml
+
+import (
+	"os"
+	"testing"
+)
+
+func TestReadBzip2CompressedDataSet(t *testing.T) {
+
+	expected, err := ReadCSVDataSet("testdata/wine.csv")
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+
+	//The decompressor is selected from the extension
+	dataSet, err := ReadCSVDataSet("testdata/wine.csv.bz2")
+	if err != nil {
+		t.Fatalf("error reading compressed dataset: %v", err)
+	}
+	if len(dataSet) != len(expected) || len(dataSet) != 20 {
+		t.Fatalf("expected %d examples, found %d", len(expected), len(dataSet))
+	}
+	for i := range dataSet {
+		if dataSet[i].Label != expected[i].Label || !equalFloats(dataSet[i].Features, expected[i].Features) {
+			t.Fatalf("example %d: expected %v, found %v", i, expected[i], dataSet[i])
+		}
+	}
+
+	//Or provided by the caller
+	file, err := os.Open("testdata/wine.csv.bz2")
+	if err != nil {
+		t.Fatalf("error opening dataset: %v", err)
+	}
+	defer file.Close()
+	options := DefaultCSVOptions()
+	options.Decompressor = Bzip2Decompressor
+	dataSet, _, err = ReadCSVDataSetFrom(file, options)
+	if err != nil || len(dataSet) != len(expected) {
+		t.Fatalf("expected %d examples, found %d (%v)", len(expected), len(dataSet), err)
+	}
+}
+
+func TestDecompressorForFileWithoutBuiltIn(t *testing.T) {
+	if _, err := DecompressorForFile("data.csv.zst"); err == nil {
+		t.Fatalf("expected an error for a .zst file")
+	}
+}
@@ -0,0 +1,48 @@
+package ml
+
+import (
+	"errors"
+)
+
+//Errors returned (possibly wrapped) by the package, to be checked with errors.Is
+var (
+	//ErrEmptyDataset is returned when a dataset without examples is provided
+	ErrEmptyDataset = errors.New("empty data set")
+	//ErrFeatureParse is returned when a feature value can not be parsed
+	ErrFeatureParse = errors.New("error parsing feature value")
+	//ErrLabelParse is returned when a label can not be parsed
+	ErrLabelParse = errors.New("error parsing label")
+	//ErrFeatureCountMismatch is returned when examples or models do not have the expected number of features
+	ErrFeatureCountMismatch = errors.New("feature count mismatch")
+	//ErrHeaderMismatch is returned when files read as a single dataset have different headers
+	ErrHeaderMismatch = errors.New("header mismatch")
+	//ErrModelCorrupt is returned when a model file can not be decoded or is inconsistent
+	ErrModelCorrupt = errors.New("model file appears empty or corrupt")
+	//ErrUnlabeled is returned when unlabeled examples are used for training or testing
+	ErrUnlabeled = errors.New("example has no label")
+)
+
+//causeError matches a sentinel error with errors.Is, while unwrapping to the error that caused it,
+//so both errors.Is(err, ErrFeatureParse) and errors.As(err, &numError) work
+type causeError struct {
+	sentinel error
+	cause    error
+	message  string
+}
+
+//newCauseError returns an error with the provided message, matching the sentinel and wrapping the cause
+func newCauseError(sentinel error, cause error, message string) error {
+	return &causeError{sentinel: sentinel, cause: cause, message: message}
+}
+
+func (e *causeError) Error() string {
+	return e.message
+}
+
+func (e *causeError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *causeError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,65 @@
+package ml
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+
+	labeled := []Example{{Features: []float64{1, 2}, Label: 1}}
+
+	tests := []struct {
+		name     string
+		err      func() error
+		sentinel error
+	}{
+		{"empty dataset", func() error {
+			_, err := Train([]Example{}, 0.1, 1)
+			return err
+		}, ErrEmptyDataset},
+		{"feature parse", func() error {
+			_, _, err := ReadCSVDataSetFrom(strings.NewReader(testCSVHeader+"1;2;x;4;5;6;7;8;9;10;5\n"), DefaultCSVOptions())
+			return err
+		}, ErrFeatureParse},
+		{"label parse", func() error {
+			_, _, err := ReadCSVDataSetFrom(strings.NewReader(testCSVHeader+"1;2;3;4;5;6;7;8;9;10;five\n"), DefaultCSVOptions())
+			return err
+		}, ErrLabelParse},
+		{"feature count mismatch", func() error {
+			_, err := DiffModels(Model{Coeficients: []float64{1}}, Model{Coeficients: []float64{1, 2}})
+			return err
+		}, ErrFeatureCountMismatch},
+		{"model corrupt", func() error {
+			_, err := LoadModelFrom(strings.NewReader("{\"Bias\": "))
+			return err
+		}, ErrModelCorrupt},
+		{"unlabeled", func() error {
+			_, err := Train([]Example{labeled[0], {Features: []float64{1, 2}, Unlabeled: true}}, 0.1, 1)
+			return err
+		}, ErrUnlabeled},
+	}
+	for _, test := range tests {
+		if err := test.err(); !errors.Is(err, test.sentinel) {
+			t.Fatalf("%s: expected %v, found %v", test.name, test.sentinel, err)
+		}
+	}
+}
+
+func TestSentinelErrorsKeepTheirCause(t *testing.T) {
+
+	_, _, err := ReadCSVDataSetFrom(strings.NewReader(testCSVHeader+"1;2;x;4;5;6;7;8;9;10;5\n"), DefaultCSVOptions())
+	var numError *strconv.NumError
+	if !errors.As(err, &numError) || numError.Num != "x" {
+		t.Fatalf("expected a *strconv.NumError for x, found %v", err)
+	}
+
+	_, err = LoadModelFrom(strings.NewReader("{\"Bias\": }"))
+	var syntaxError *json.SyntaxError
+	if !errors.Is(err, ErrModelCorrupt) || !errors.As(err, &syntaxError) {
+		t.Fatalf("expected a *json.SyntaxError matching ErrModelCorrupt, found %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+//BootstrapCI estimates a 95% confidence interval for the model loss (RMSE) on the dataset,
+//by resampling it n times with replacement.
+//Returns the 2.5 and 97.5 percentiles of the resampled losses, and the loss on the whole dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+func BootstrapCI(model Model, data []Example, n int, seed int64) (lo, hi, point float64) {
+
+	squaredErrors := make([]float64, len(data))
+	sumError := 0.0
+	for i, example := range normalizeDataSet(model, data) {
+		error := Predict(model, example) - example.Label
+		squaredErrors[i] = error * error
+		sumError += squaredErrors[i]
+	}
+	point = math.Sqrt(sumError / float64(len(data)))
+
+	random := rand.New(rand.NewSource(seed))
+	losses := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sampleError := 0.0
+		for j := 0; j < len(data); j++ {
+			sampleError += squaredErrors[random.Intn(len(data))]
+		}
+		losses[i] = math.Sqrt(sampleError / float64(len(data)))
+	}
+	sort.Float64s(losses)
+
+	return percentile(losses, 0.025), percentile(losses, 0.975), point
+}
+
+//percentile returns the p-th percentile (0 <= p <= 1) of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	return sorted[int(math.Round(p*float64(len(sorted)-1)))]
+}
+
+//McNemar compares two models on the same dataset with McNemar's test (with continuity correction).
+//A prediction is considered correct when, rounded to the nearest integer, it equals the label.
+//Returns the chi-square statistic and its p-value: a small p-value means the models
+//disagree more than chance would explain.
+//Each model normalizes its own copy of the features, the dataset is not modified.
+func McNemar(a, b Model, data []Example) (statistic, pValue float64) {
+
+	//onlyA and onlyB count the examples only one of the models gets right
+	onlyA, onlyB := 0.0, 0.0
+	for _, example := range data {
+		correctA := isCorrect(a, example)
+		correctB := isCorrect(b, example)
+		if correctA && !correctB {
+			onlyA++
+		} else if correctB && !correctA {
+			onlyB++
+		}
+	}
+
+	if onlyA+onlyB == 0 {
+		return 0, 1
+	}
+
+	difference := math.Max(math.Abs(onlyA-onlyB)-1, 0)
+	statistic = difference * difference / (onlyA + onlyB)
+	//Survival function of the chi-square distribution with 1 degree of freedom
+	pValue = math.Erfc(math.Sqrt(statistic / 2))
+
+	return statistic, pValue
+}
+
+//isCorrect tells whether the model prediction for an example without normalized features,
+//rounded to the nearest integer, matches the label
+func isCorrect(model Model, example Example) bool {
+	return math.Round(Predict(model, normalizeExample(model, example))) == example.Label
+}
+
+//normalizeExample returns a copy of the example with its features normalized with the model limits
+func normalizeExample(model Model, example Example) Example {
+	normalized := []Example{{Features: append([]float64(nil), example.Features...), Label: example.Label,
+		Unlabeled: example.Unlabeled}}
+	NormalizeDatasetFeaturesWithLimits(normalized, model.MaxFeatureValues, model.MinFeatureValues)
+	return normalized[0]
+}
+
+//PermutationImportance measures how much the model relies on each of the provided features:
+//the values of a feature are shuffled across the dataset, and its importance is the resulting
+//increase of the loss (RMSE). Features the model ignores have an importance close to zero.
+//The dataset features must not be normalized, and the dataset is not modified.
+func PermutationImportance(model Model, data []Example, features []int, seed int64) map[int]float64 {
+
+	normalized := normalizeDataSet(model, data)
+	baseline := rmse(model, normalized)
+
+	random := rand.New(rand.NewSource(seed))
+	importance := make(map[int]float64, len(features))
+	for _, feature := range features {
+		original := make([]float64, len(normalized))
+		for i := range normalized {
+			original[i] = normalized[i].Features[feature]
+		}
+		random.Shuffle(len(normalized), func(i, j int) {
+			normalized[i].Features[feature], normalized[j].Features[feature] =
+				normalized[j].Features[feature], normalized[i].Features[feature]
+		})
+
+		importance[feature] = rmse(model, normalized) - baseline
+
+		for i := range normalized {
+			normalized[i].Features[feature] = original[i]
+		}
+	}
+
+	return importance
+}
+
+//rmse returns the root mean squared error of the model on a normalized dataset
+func rmse(model Model, data []Example) float64 {
+	sumError := 0.0
+	for _, example := range data {
+		error := Predict(model, example) - example.Label
+		sumError += error * error
+	}
+	return math.Sqrt(sumError / float64(len(data)))
+}
+
+//PredictMasked makes a prediction for a single example with normalized features,
+//ignoring the contribution of the masked features
+func PredictMasked(model Model, example Example, masked map[int]bool) float64 {
+
+	result := model.Bias
+
+	for i := 0; i < len(example.Features); i++ {
+		if !masked[i] {
+			result += model.Coeficients[i] * example.Features[i]
+		}
+	}
+	return result
+
+}
+
+//Ablation returns the increase of the loss (RMSE) on the dataset when the masked features are ignored.
+//The dataset features must not be normalized, and the dataset is not modified.
+func Ablation(model Model, data []Example, masked map[int]bool) float64 {
+
+	sumError, sumMaskedError := 0.0, 0.0
+	for _, example := range normalizeDataSet(model, data) {
+		error := Predict(model, example) - example.Label
+		maskedError := PredictMasked(model, example, masked) - example.Label
+		sumError += error * error
+		sumMaskedError += maskedError * maskedError
+	}
+
+	return math.Sqrt(sumMaskedError/float64(len(data))) - math.Sqrt(sumError/float64(len(data)))
+}
+
+//PruneToTopN returns a copy of the model keeping only the n most important features, with the coefficients
+//of the others set to zero. Features are ranked by their validation impact (see Ablation), ties broken by the
+//absolute value of their coefficient.
+//Returns the pruned model and the loss (RMSE) on the validation data before and after pruning.
+//The validation data features must not be normalized, and the data is not modified.
+func PruneToTopN(model Model, valData []Example, n int) (Model, float64, float64) {
+
+	pruned := model.Clone()
+	before := RMSE(model, valData)
+	if n >= len(model.Coeficients) {
+		return pruned, before, before
+	}
+	if n < 0 {
+		n = 0
+	}
+
+	impact := make([]float64, len(model.Coeficients))
+	for feature := range impact {
+		impact[feature] = Ablation(model, valData, map[int]bool{feature: true})
+	}
+
+	features := make([]int, len(model.Coeficients))
+	for i := range features {
+		features[i] = i
+	}
+	sort.SliceStable(features, func(i, j int) bool {
+		a, b := features[i], features[j]
+		if impact[a] != impact[b] {
+			return impact[a] > impact[b]
+		}
+		return math.Abs(model.Coeficients[a]) > math.Abs(model.Coeficients[b])
+	})
+
+	for _, feature := range features[n:] {
+		pruned.Coeficients[feature] = 0
+	}
+	//The fingerprint of the original model no longer matches
+	pruned.Fingerprint = ""
+	return pruned, before, RMSE(pruned, valData)
+}
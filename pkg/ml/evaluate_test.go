@@ -0,0 +1,149 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+//informativeDataset returns a dataset with 6 features whose label only depends on the first 3
+func informativeDataset(n int, seed int64) []Example {
+	data := GenerateSyntheticDataset(n, 6, 0, seed)
+	for i := range data {
+		features := data[i].Features
+		data[i].Label = 1 + 2*features[0] - 3*features[1] + features[2]
+	}
+	return data
+}
+
+//trainCopy trains a model on a copy of the dataset, leaving the dataset features unnormalized
+func trainCopy(t *testing.T, data []Example, options TrainOptions) Model {
+	indices := make([]int, len(data))
+	for i := range indices {
+		indices[i] = i
+	}
+	model, _, err := TrainWithOptions(copyExamples(data, indices), options)
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	return model
+}
+
+func TestPruneToTopNKeepsInformativeFeatures(t *testing.T) {
+
+	model := trainCopy(t, informativeDataset(500, 1), TrainOptions{LearningRate: 0.01, NumEpochs: 200})
+	validation := informativeDataset(200, 2)
+
+	pruned, before, after := PruneToTopN(model, validation, 3)
+
+	active := pruned.ActiveFeatures()
+	if len(active) != 3 || active[0] != 0 || active[1] != 1 || active[2] != 2 {
+		t.Fatalf("expected features [0 1 2] to be kept, found %v", active)
+	}
+	if before != RMSE(model, validation) || after != RMSE(pruned, validation) {
+		t.Fatalf("expected the validation RMSE before (%v) and after (%v) pruning, found %v and %v",
+			RMSE(model, validation), RMSE(pruned, validation), before, after)
+	}
+	if after-before > 0.01 {
+		t.Fatalf("expected pruning to keep the validation RMSE, found %v before and %v after", before, after)
+	}
+	if model.ActiveCount() != 6 {
+		t.Fatalf("expected the original model to be unchanged")
+	}
+}
+
+//indexRange returns the indices from start (included) to end (excluded)
+func indexRange(start, end int) []int {
+	indices := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+func TestBootstrapCINarrowsWithMoreData(t *testing.T) {
+
+	data := GenerateSyntheticDataset(6000, 3, 0.5, 1)
+	model := trainCopy(t, data[:1000], TrainOptions{LearningRate: 0.01, NumEpochs: 20})
+
+	smallLo, smallHi, smallPoint := BootstrapCI(model, data[1000:1100], 500, 1)
+	largeLo, largeHi, largePoint := BootstrapCI(model, data[1000:6000], 500, 1)
+
+	if smallLo > smallPoint || smallPoint > smallHi || largeLo > largePoint || largePoint > largeHi {
+		t.Fatalf("expected the intervals to bracket the point estimates, found [%v %v] %v and [%v %v] %v",
+			smallLo, smallHi, smallPoint, largeLo, largeHi, largePoint)
+	}
+	if smallPoint != RMSE(model, data[1000:1100]) || largePoint != RMSE(model, data[1000:6000]) {
+		t.Fatalf("expected the point estimates to be the RMSE of the unchanged datasets, found %v and %v",
+			smallPoint, largePoint)
+	}
+	if largeHi-largeLo >= smallHi-smallLo {
+		t.Fatalf("expected a narrower interval with more data, found %v with 100 examples and %v with 5000",
+			smallHi-smallLo, largeHi-largeLo)
+	}
+}
+
+func TestMcNemarBetterModel(t *testing.T) {
+
+	//Features are not changed by the normalization of the models
+	limits := func(model Model) Model {
+		model.MinFeatureValues, model.MaxFeatureValues = []float64{0}, []float64{1}
+		return model
+	}
+	//a always predicts 5, b predicts the label
+	a := limits(Model{Bias: 5, Coeficients: []float64{0}})
+	b := limits(Model{Bias: 0, Coeficients: []float64{1}})
+
+	data := make([]Example, 50)
+	for i := range data {
+		data[i] = example(float64(i%10), float64(i%10))
+	}
+
+	statistic, pValue := McNemar(a, b, data)
+	if statistic <= 0 || pValue > 0.001 {
+		t.Fatalf("expected a small p-value, found statistic %v and p-value %v", statistic, pValue)
+	}
+	if _, pValue := McNemar(b, b, data); pValue != 1 {
+		t.Fatalf("expected a p-value of 1 for identical models, found %v", pValue)
+	}
+}
+
+func TestPermutationImportance(t *testing.T) {
+
+	data := informativeDataset(500, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 200})
+	first := data[0].Features[1]
+
+	importance := PermutationImportance(model, data, []int{1, 4}, 1)
+
+	if importance[1] < 1 {
+		t.Fatalf("expected a large importance for the predictive feature, found %v", importance[1])
+	}
+	if math.Abs(importance[4]) > 0.01 {
+		t.Fatalf("expected an importance close to 0 for the irrelevant feature, found %v", importance[4])
+	}
+	if data[0].Features[1] != first {
+		t.Fatalf("expected the dataset to be unchanged")
+	}
+}
+
+func TestPredictMaskedAndAblation(t *testing.T) {
+
+	model := testModel()
+	normalized := normalizeExample(model, example(0, 1, 1, 1))
+	if prediction := PredictMasked(model, normalized, nil); prediction != Predict(model, normalized) {
+		t.Fatalf("expected the unmasked prediction %v, found %v", Predict(model, normalized), prediction)
+	}
+	//Without feature 2, whose contribution is -0.5
+	if prediction := PredictMasked(model, normalized, map[int]bool{2: true}); prediction != Predict(model, normalized)+0.5 {
+		t.Fatalf("expected prediction %v, found %v", Predict(model, normalized)+0.5, prediction)
+	}
+
+	data := informativeDataset(500, 1)
+	trained := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 200})
+	if increase := Ablation(trained, data, map[int]bool{1: true}); increase < 1 {
+		t.Fatalf("expected masking a predictive feature to increase the RMSE, found %v", increase)
+	}
+	if increase := Ablation(trained, data, map[int]bool{4: true, 5: true}); math.Abs(increase) > 0.01 {
+		t.Fatalf("expected masking irrelevant features to keep the RMSE, found %v", increase)
+	}
+}
@@ -0,0 +1,113 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+//Grid search parameters, the values TrainOptions are tuned on
+const (
+	ParamLearningRate = "learning_rate"
+	ParamNumEpochs    = "epochs"
+)
+
+//GridResult is the cross-validated score of a combination of parameters
+type GridResult struct {
+	Params map[string]float64
+	//Score is the mean of the metric over the validation folds
+	Score float64
+}
+
+//GridSearch cross-validates every combination of the parameter values in the grid
+//(ParamLearningRate and ParamNumEpochs) over the given number of folds, and returns the best
+//parameters and score along with all the results, in the order they were evaluated.
+//Every combination is trained with the base options, with the grid values replacing theirs.
+//The best score is the highest if maximize is true (e.g. R2), the lowest otherwise (e.g. RMSE).
+//Folds are assigned randomly with the provided seed, so the search is deterministic,
+//and the dataset is not modified.
+func GridSearch(data []Example, base TrainOptions, grid map[string][]float64, metric Metric, maximize bool,
+	folds int, seed int64) (map[string]float64, float64, []GridResult, error) {
+
+	if folds < 2 {
+		return nil, 0, nil, fmt.Errorf("expected at least 2 folds, found %d", folds)
+	}
+
+	names := make([]string, 0, len(grid))
+	for name, values := range grid {
+		if name != ParamLearningRate && name != ParamNumEpochs {
+			return nil, 0, nil, fmt.Errorf("unknown grid search parameter %s", name)
+		}
+		if len(values) == 0 {
+			return nil, 0, nil, fmt.Errorf("no values for grid search parameter %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	//Each example is assigned to a random fold, as a group of GroupKFold
+	groups := make([]int, len(data))
+	for i, position := range rand.New(rand.NewSource(seed)).Perm(len(data)) {
+		groups[i] = position % folds
+	}
+	splits, err := GroupKFold(data, groups, folds)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	results := make([]GridResult, 0)
+	best := -1
+	//combination holds the index of the current value of each parameter
+	combination := make([]int, len(names))
+	for {
+		params := map[string]float64{ParamLearningRate: base.LearningRate, ParamNumEpochs: float64(base.NumEpochs)}
+		for i, name := range names {
+			params[name] = grid[name][combination[i]]
+		}
+		options := base
+		options.LearningRate = params[ParamLearningRate]
+		options.NumEpochs = int(params[ParamNumEpochs])
+
+		score := 0.0
+		for _, split := range splits {
+			model, _, err := TrainWithOptions(copyExamples(data, split.Train), options)
+			if err != nil {
+				return nil, 0, nil, fmt.Errorf("error training with %v: %w", params, err)
+			}
+			score += metric(model, copyExamples(data, split.Validation))
+		}
+		results = append(results, GridResult{Params: params, Score: score / float64(len(splits))})
+
+		last := len(results) - 1
+		if best < 0 || (maximize && results[last].Score > results[best].Score) ||
+			(!maximize && results[last].Score < results[best].Score) {
+			best = last
+		}
+
+		//Advance to the next combination, the last parameter changing fastest
+		i := len(names) - 1
+		for ; i >= 0; i-- {
+			combination[i]++
+			if combination[i] < len(grid[names[i]]) {
+				break
+			}
+			combination[i] = 0
+		}
+		if i < 0 {
+			break
+		}
+	}
+
+	return results[best].Params, results[best].Score, results, nil
+}
+
+//copyExamples returns a copy of the examples at the provided indices, features included,
+//so they can be normalized without modifying the dataset
+func copyExamples(data []Example, indices []int) []Example {
+	examples := make([]Example, len(indices))
+	for i, index := range indices {
+		examples[i] = data[index]
+		examples[i].Features = cloneFloats(data[index].Features)
+	}
+	return examples
+}
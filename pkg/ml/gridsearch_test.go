@@ -0,0 +1,54 @@
+package ml
+
+import (
+	"testing"
+)
+
+func TestGridSearchBestMatchesBruteForce(t *testing.T) {
+
+	data := GenerateSyntheticDataset(200, 3, 0.1, 1)
+	base := TrainOptions{LearningRate: 0.01, NumEpochs: 10}
+	grid := map[string][]float64{ParamLearningRate: {0.001, 0.05}, ParamNumEpochs: {2, 20}}
+
+	bestParams, bestScore, results, err := GridSearch(data, base, grid, RMSE, false, 3, 42)
+	if err != nil {
+		t.Fatalf("error in grid search: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, found %d", len(results))
+	}
+
+	//Evaluate every combination on its own, with the same folds
+	bruteForceScore := 0.0
+	var bruteForceParams map[string]float64
+	for _, learningRate := range grid[ParamLearningRate] {
+		for _, numEpochs := range grid[ParamNumEpochs] {
+			_, score, _, err := GridSearch(data, base,
+				map[string][]float64{ParamLearningRate: {learningRate}, ParamNumEpochs: {numEpochs}}, RMSE, false, 3, 42)
+			if err != nil {
+				t.Fatalf("error in grid search: %v", err)
+			}
+			if bruteForceParams == nil || score < bruteForceScore {
+				bruteForceScore = score
+				bruteForceParams = map[string]float64{ParamLearningRate: learningRate, ParamNumEpochs: numEpochs}
+			}
+		}
+	}
+
+	if bestScore != bruteForceScore || bestParams[ParamLearningRate] != bruteForceParams[ParamLearningRate] ||
+		bestParams[ParamNumEpochs] != bruteForceParams[ParamNumEpochs] {
+		t.Fatalf("expected best %v (%v), found %v (%v)", bruteForceParams, bruteForceScore, bestParams, bestScore)
+	}
+}
+
+func TestGridSearchInvalidFolds(t *testing.T) {
+
+	data := GenerateSyntheticDataset(20, 2, 0, 1)
+	for _, folds := range []int{0, 1} {
+		_, _, _, err := GridSearch(data, TrainOptions{LearningRate: 0.01, NumEpochs: 1},
+			map[string][]float64{ParamLearningRate: {0.01}}, RMSE, false, folds, 1)
+		if err == nil {
+			t.Fatalf("expected an error with %d folds", folds)
+		}
+	}
+}
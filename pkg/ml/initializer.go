@@ -0,0 +1,35 @@
+package ml
+
+import (
+	"math/rand"
+)
+
+//Initializer sets the initial values of the model coefficients before training,
+//drawing any random numbers from the provided source
+type Initializer func(coeficients []float64, random *rand.Rand)
+
+//ZeroInitializer leaves all coefficients at zero. It is the default used by Train.
+func ZeroInitializer(coeficients []float64, random *rand.Rand) {
+	for i := range coeficients {
+		coeficients[i] = 0
+	}
+}
+
+//UniformInitializer draws the coefficients uniformly from [-scale, scale)
+func UniformInitializer(scale float64) Initializer {
+	return func(coeficients []float64, random *rand.Rand) {
+		for i := range coeficients {
+			coeficients[i] = (random.Float64()*2 - 1) * scale
+		}
+	}
+}
+
+//NormalInitializer draws the coefficients from a normal distribution with mean 0
+//and the provided standard deviation
+func NormalInitializer(std float64) Initializer {
+	return func(coeficients []float64, random *rand.Rand) {
+		for i := range coeficients {
+			coeficients[i] = random.NormFloat64() * std
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package ml
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestInitializers(t *testing.T) {
+
+	data := GenerateSyntheticDataset(50, 4, 0.5, 1)
+	train := func(initializer Initializer, seed int64) Model {
+		return trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 2, Initializer: initializer, Seed: seed})
+	}
+
+	if a, b := train(nil, 1), train(ZeroInitializer, 2); a.Bias != b.Bias || !equalFloats(a.Coeficients, b.Coeficients) {
+		t.Fatalf("expected zero initialization to be deterministic, found %v and %v", a, b)
+	}
+
+	normal := NormalInitializer(0.1)
+	if a, b := train(normal, 1), train(normal, 1); !equalFloats(a.Coeficients, b.Coeficients) {
+		t.Fatalf("expected the same seed to train the same model, found %v and %v", a, b)
+	}
+	if a, b := train(normal, 1), train(normal, 2); equalFloats(a.Coeficients, b.Coeficients) {
+		t.Fatalf("expected different seeds to train different models, found %v", a)
+	}
+
+	first, second := make([]float64, 100), make([]float64, 100)
+	normal(first, rand.New(rand.NewSource(1)))
+	normal(second, rand.New(rand.NewSource(2)))
+	if equalFloats(first, second) {
+		t.Fatalf("expected different seeds to draw different initial coefficients")
+	}
+
+	UniformInitializer(0.5)(first, rand.New(rand.NewSource(1)))
+	for i, c := range first {
+		if c < -0.5 || c >= 0.5 {
+			t.Fatalf("coefficient %d: expected a value in [-0.5, 0.5), found %v", i, c)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ml
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+//LatencyHistogram records durations in power of two nanosecond buckets:
+//bucket i counts durations in [2^(i-1), 2^i) nanoseconds (bucket 0 counts zero durations).
+//Percentiles are approximated by the upper bound of the bucket they fall in.
+type LatencyHistogram struct {
+	Buckets [65]int
+	Count   int
+}
+
+//Record adds a duration to the histogram
+func (h *LatencyHistogram) Record(duration time.Duration) {
+	if duration < 0 {
+		duration = 0
+	}
+	h.Buckets[bits.Len64(uint64(duration))]++
+	h.Count++
+}
+
+//Percentile returns the approximate p-th percentile (0 <= p <= 1) of the recorded durations
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(h.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	seen := 0
+	for i, count := range h.Buckets {
+		seen += count
+		if seen >= rank {
+			if i == 0 {
+				return 0
+			}
+			if i == 64 {
+				return time.Duration(math.MaxInt64)
+			}
+			return time.Duration(uint64(1)<<uint(i) - 1)
+		}
+	}
+	return time.Duration(math.MaxInt64)
+}
+
+//P50 returns the approximate median duration
+func (h *LatencyHistogram) P50() time.Duration {
+	return h.Percentile(0.5)
+}
+
+//P95 returns the approximate 95th percentile duration
+func (h *LatencyHistogram) P95() time.Duration {
+	return h.Percentile(0.95)
+}
+
+//P99 returns the approximate 99th percentile duration
+func (h *LatencyHistogram) P99() time.Duration {
+	return h.Percentile(0.99)
+}
+
+//PredictBatch makes a prediction for each example, given a model.
+//As with Predict, the example features must be normalized.
+func PredictBatch(model Model, examples []Example) []float64 {
+	return PredictBatchTimed(model, examples, nil)
+}
+
+//PredictBatchTimed makes a prediction for each example, recording the latency of every
+//prediction in the histogram (if not nil)
+func PredictBatchTimed(model Model, examples []Example, histogram *LatencyHistogram) []float64 {
+
+	predictions := make([]float64, len(examples))
+	for i, example := range examples {
+		if histogram == nil {
+			predictions[i] = Predict(model, example)
+			continue
+		}
+		start := time.Now()
+		predictions[i] = Predict(model, example)
+		histogram.Record(time.Since(start))
+	}
+	return predictions
+}
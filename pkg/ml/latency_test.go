@@ -0,0 +1,52 @@
+package ml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogramPercentiles(t *testing.T) {
+
+	var histogram LatencyHistogram
+	if histogram.P50() != 0 {
+		t.Fatalf("expected 0 for an empty histogram, found %v", histogram.P50())
+	}
+	//90 durations of 100ns, 9 of 10µs and 1 of 1ms
+	for i := 0; i < 100; i++ {
+		duration := 100 * time.Nanosecond
+		if i >= 90 {
+			duration = 10 * time.Microsecond
+		}
+		if i == 99 {
+			duration = time.Millisecond
+		}
+		histogram.Record(duration)
+	}
+
+	//Percentiles are the upper bound of their power of two bucket
+	if histogram.P50() != 127 || histogram.P95() != 16383 || histogram.P99() != 16383 || histogram.Percentile(1) != 1048575 {
+		t.Fatalf("expected percentiles 127ns, 16383ns, 16383ns and 1048575ns, found %v, %v, %v and %v",
+			histogram.P50(), histogram.P95(), histogram.P99(), histogram.Percentile(1))
+	}
+}
+
+func TestPredictBatchTimed(t *testing.T) {
+
+	model := testModel()
+	examples := GenerateSyntheticDataset(1000, 3, 0, 1)
+	var histogram LatencyHistogram
+
+	predictions := PredictBatchTimed(model, examples, &histogram)
+
+	if histogram.Count != len(examples) {
+		t.Fatalf("expected %d latencies, found %d", len(examples), histogram.Count)
+	}
+	if histogram.P50() > histogram.P95() || histogram.P95() > histogram.P99() || histogram.P99() > time.Second {
+		t.Fatalf("expected ordered percentiles below a second, found %v, %v and %v", histogram.P50(), histogram.P95(), histogram.P99())
+	}
+	for i, prediction := range PredictBatch(model, examples) {
+		if predictions[i] != prediction || prediction != Predict(model, examples[i]) {
+			t.Fatalf("example %d: expected prediction %v, found %v", i, Predict(model, examples[i]), predictions[i])
+		}
+	}
+}
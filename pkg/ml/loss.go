@@ -0,0 +1,111 @@
+package ml
+
+import (
+	"math"
+)
+
+//Loss is a loss function minimized by the training loop
+type Loss interface {
+	//Value is the loss of a single prediction
+	Value(prediction, label float64) float64
+	//Gradient is the derivative of the loss with respect to the prediction
+	Gradient(prediction, label float64) float64
+}
+
+//SquaredError is the loss for regression, and the default loss used by Train
+type SquaredError struct{}
+
+//Value returns half the squared error
+func (SquaredError) Value(prediction, label float64) float64 {
+	error := prediction - label
+	return error * error / 2
+}
+
+//Gradient returns the error
+func (SquaredError) Gradient(prediction, label float64) float64 {
+	return prediction - label
+}
+
+//CrossEntropy is the logistic loss for binary classification:
+//the prediction is taken as a logit and labels are expected in [0, 1]
+type CrossEntropy struct{}
+
+//Value returns the cross entropy between the label and the predicted probability
+func (CrossEntropy) Value(prediction, label float64) float64 {
+	//log(1+e^p) - label*p, computed without overflowing for large predictions
+	return math.Max(prediction, 0) + math.Log1p(math.Exp(-math.Abs(prediction))) - label*prediction
+}
+
+//Gradient returns the difference between the predicted probability and the label
+func (CrossEntropy) Gradient(prediction, label float64) float64 {
+	return sigmoid(prediction) - label
+}
+
+//Hinge is the SVM loss for binary classification: labels are expected to be 0 or 1,
+//and predictions are penalized unless they are on the right side of the margin
+type Hinge struct{}
+
+//Value returns the hinge loss
+func (Hinge) Value(prediction, label float64) float64 {
+	return math.Max(0, 1-signedLabel(label)*prediction)
+}
+
+//Gradient returns the hinge loss subgradient
+func (Hinge) Gradient(prediction, label float64) float64 {
+	y := signedLabel(label)
+	if y*prediction < 1 {
+		return -y
+	}
+	return 0
+}
+
+//sigmoid computes 1/(1+e^-x) without overflowing:
+//for negative x it uses the equivalent e^x/(1+e^x)
+func sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	exp := math.Exp(x)
+	return exp / (1 + exp)
+}
+
+//signedLabel maps a 0/1 label to -1/+1
+func signedLabel(label float64) float64 {
+	if label > 0.5 {
+		return 1
+	}
+	return -1
+}
+
+//FocalLoss is a variant of CrossEntropy that down-weights well classified examples,
+//so that training focuses on the hard ones. Useful for very imbalanced datasets.
+//Gamma controls how fast easy examples are down-weighted (0 is plain cross entropy, 2 is common),
+//Alpha is the weight of the positive class (and 1-Alpha the weight of the negative class).
+type FocalLoss struct {
+	Gamma float64
+	Alpha float64
+}
+
+//Value returns the focal loss
+func (f FocalLoss) Value(prediction, label float64) float64 {
+	pt, alpha, _ := f.target(prediction, label)
+	return -alpha * math.Pow(1-pt, f.Gamma) * math.Log(pt)
+}
+
+//Gradient returns the derivative of the focal loss with respect to the prediction (logit)
+func (f FocalLoss) Gradient(prediction, label float64) float64 {
+	pt, alpha, sign := f.target(prediction, label)
+	return sign * alpha * math.Pow(1-pt, f.Gamma) * (f.Gamma*pt*math.Log(pt) - (1 - pt))
+}
+
+//target returns the probability assigned to the true class, its weight,
+//and the sign of the derivative of that probability with respect to the prediction
+func (f FocalLoss) target(prediction, label float64) (float64, float64, float64) {
+	pt, alpha, sign := sigmoid(prediction), f.Alpha, 1.0
+	if label < 0.5 {
+		//1 - sigmoid(x) = sigmoid(-x), without losing precision when sigmoid(x) is close to 1
+		pt, alpha, sign = sigmoid(-prediction), 1-f.Alpha, -1.0
+	}
+	//Avoid log(0) for confidently wrong predictions
+	return math.Max(pt, 1e-15), alpha, sign
+}
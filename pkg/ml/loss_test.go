@@ -0,0 +1,128 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+//classificationDataset returns examples with 2 features in [0, 10) labeled 1 when
+//their sum is above threshold, skipping those closer than margin to the boundary.
+//With noise, labels are flipped with that probability.
+func classificationDataset(n int, threshold, margin, noise float64, seed int64) []Example {
+	random := rand.New(rand.NewSource(seed))
+	data := make([]Example, 0, n)
+	for len(data) < n {
+		x, y := random.Float64()*10, random.Float64()*10
+		if math.Abs(x+y-threshold) < margin {
+			continue
+		}
+		label := 0.0
+		if x+y > threshold {
+			label = 1
+		}
+		if random.Float64() < noise {
+			label = 1 - label
+		}
+		data = append(data, example(label, x, y))
+	}
+	return data
+}
+
+//recall returns the fraction of positive examples predicted as positive (a positive logit)
+func recall(model Model, data []Example) float64 {
+	positives, found := 0, 0
+	for _, example := range data {
+		if example.Label < 0.5 {
+			continue
+		}
+		positives++
+		if Predict(model, normalizeExample(model, example)) > 0 {
+			found++
+		}
+	}
+	return ratio(found, positives)
+}
+
+func TestTrainHingeLossSeparable(t *testing.T) {
+
+	data := classificationDataset(200, 10, 1, 0, 1)
+	lossValues := []float64{}
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.05, NumEpochs: 100, Loss: Hinge{},
+		EpochListener: func(stats EpochStats) { lossValues = append(lossValues, stats.LossValue) }})
+
+	if last := lossValues[len(lossValues)-1]; last >= lossValues[0] || last > 0.01 {
+		t.Fatalf("expected the hinge loss to converge, found %v after the first epoch and %v after the last", lossValues[0], last)
+	}
+	for i, example := range data {
+		if (Predict(model, normalizeExample(model, example)) > 0) != (example.Label == 1) {
+			t.Fatalf("example %d misclassified: %v", i, example)
+		}
+	}
+}
+
+func TestFocalLossImprovesMinorityRecall(t *testing.T) {
+
+	//About 8% of positives, with noisy labels
+	data := classificationDataset(2000, 16, 0, 0.02, 1)
+	options := TrainOptions{LearningRate: 0.01, NumEpochs: 20, Loss: CrossEntropy{}}
+	crossEntropy := trainCopy(t, data, options)
+	options.Loss = FocalLoss{Gamma: 2, Alpha: 0.75}
+	focal := trainCopy(t, data, options)
+
+	if recall(focal, data) <= recall(crossEntropy, data) {
+		t.Fatalf("expected focal loss to improve the minority class recall, found %v with cross entropy and %v with focal loss",
+			recall(crossEntropy, data), recall(focal, data))
+	}
+}
+
+func TestLossGradients(t *testing.T) {
+
+	losses := []Loss{SquaredError{}, CrossEntropy{}, FocalLoss{Gamma: 2, Alpha: 0.25}, FocalLoss{Gamma: 0.5, Alpha: 0.9}}
+	const h = 1e-6
+	for _, loss := range losses {
+		for _, label := range []float64{0, 1} {
+			for _, prediction := range []float64{-3, -0.5, 0.2, 4} {
+				numerical := (loss.Value(prediction+h, label) - loss.Value(prediction-h, label)) / (2 * h)
+				if gradient := loss.Gradient(prediction, label); math.Abs(gradient-numerical) > 1e-5 {
+					t.Fatalf("%T%v at prediction %v and label %v: expected gradient %v, found %v",
+						loss, loss, prediction, label, numerical, gradient)
+				}
+			}
+		}
+	}
+
+	//Without focusing and class weights, focal loss is half the cross entropy
+	focal := FocalLoss{Gamma: 0, Alpha: 0.5}
+	value, expected := focal.Value(1.5, 1), CrossEntropy{}.Value(1.5, 1)/2
+	if math.Abs(value-expected) > 1e-12 {
+		t.Fatalf("expected %v, found %v", expected, value)
+	}
+}
+
+func TestSigmoidAndLossesDoNotOverflow(t *testing.T) {
+
+	if sigmoid(1000) != 1 || sigmoid(-1000) != 0 || sigmoid(0) != 0.5 {
+		t.Fatalf("expected sigmoid values 1, 0 and 0.5, found %v, %v and %v", sigmoid(1000), sigmoid(-1000), sigmoid(0))
+	}
+	if value := sigmoid(-40); value <= 0 || math.Abs(value-math.Exp(-40)) > 1e-30 {
+		t.Fatalf("expected sigmoid(-40) to be close to e^-40, found %v", value)
+	}
+
+	losses := []Loss{CrossEntropy{}, FocalLoss{Gamma: 2, Alpha: 0.25}, Hinge{}}
+	for _, loss := range losses {
+		for _, prediction := range []float64{-1000, 1000} {
+			for _, label := range []float64{0, 1} {
+				value, gradient := loss.Value(prediction, label), loss.Gradient(prediction, label)
+				if math.IsNaN(value) || math.IsInf(value, 0) || math.IsNaN(gradient) || math.IsInf(gradient, 0) {
+					t.Fatalf("%T at prediction %v and label %v: expected finite values, found %v and gradient %v",
+						loss, prediction, label, value, gradient)
+				}
+			}
+		}
+	}
+	//A confidently wrong prediction costs about the size of the logit
+	if value := (CrossEntropy{}).Value(1000, 0); value != 1000 {
+		t.Fatalf("expected a cross entropy of 1000, found %v", value)
+	}
+}
@@ -0,0 +1,192 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+//Metric evaluates a model on a dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+type Metric func(model Model, data []Example) float64
+
+//Metrics are the available metrics, by name
+var Metrics = map[string]Metric{
+	"rmse":     RMSE,
+	"mae":      MAE,
+	"r2":       R2,
+	"accuracy": Accuracy,
+	"macro_f1": func(model Model, data []Example) float64 {
+		return NewMultiConfusionMatrix(model, data).MacroF1()
+	},
+	"micro_f1": func(model Model, data []Example) float64 {
+		return NewMultiConfusionMatrix(model, data).MicroF1()
+	},
+	//pr_auc is only meaningful for models trained with a classification loss
+	"pr_auc": PRAUC,
+}
+
+//EvaluateAll computes the named metrics (see Metrics) of the model on the dataset
+func EvaluateAll(model Model, data []Example, names []string) (map[string]float64, error) {
+
+	results := make(map[string]float64, len(names))
+	for _, name := range names {
+		metric, ok := Metrics[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown metric %s", name)
+		}
+		results[name] = metric(model, data)
+	}
+	return results, nil
+}
+
+//RMSE returns the root mean squared error of the model predictions
+func RMSE(model Model, data []Example) float64 {
+	return rmse(model, normalizeDataSet(model, data))
+}
+
+//MAE returns the mean absolute error of the model predictions
+func MAE(model Model, data []Example) float64 {
+	sumError := 0.0
+	for _, example := range normalizeDataSet(model, data) {
+		sumError += math.Abs(Predict(model, example) - example.Label)
+	}
+	return sumError / float64(len(data))
+}
+
+//R2 returns the coefficient of determination of the model predictions:
+//1 for perfect predictions, 0 for a model always predicting the mean label
+func R2(model Model, data []Example) float64 {
+	meanLabel := Summarize(data).MeanLabel
+	sumError, sumVariance := 0.0, 0.0
+	for _, example := range normalizeDataSet(model, data) {
+		error := Predict(model, example) - example.Label
+		sumError += error * error
+		sumVariance += (example.Label - meanLabel) * (example.Label - meanLabel)
+	}
+	return 1 - sumError/sumVariance
+}
+
+//Accuracy returns the fraction of predictions that, rounded to the nearest integer, match the label
+func Accuracy(model Model, data []Example) float64 {
+	correct := 0
+	for _, example := range data {
+		if isCorrect(model, example) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(data))
+}
+
+//normalizeDataSet returns a copy of the dataset with its features normalized with the model limits
+func normalizeDataSet(model Model, data []Example) []Example {
+	normalized := make([]Example, len(data))
+	for i, example := range data {
+		normalized[i] = normalizeExample(model, example)
+	}
+	return normalized
+}
+
+//EvaluationReport summarizes the evaluation of a model on a dataset
+type EvaluationReport struct {
+	Examples        int
+	RMSE            float64
+	MAE             float64
+	R2              float64
+	Accuracy        float64
+	MacroF1         float64
+	MicroF1         float64
+	ConfusionMatrix MultiConfusionMatrix
+}
+
+//Report evaluates the model on the dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+func Report(model Model, data []Example) EvaluationReport {
+
+	confusionMatrix := NewMultiConfusionMatrix(model, data)
+	return EvaluationReport{Examples: len(data),
+		RMSE:            RMSE(model, data),
+		MAE:             MAE(model, data),
+		R2:              R2(model, data),
+		Accuracy:        Accuracy(model, data),
+		MacroF1:         confusionMatrix.MacroF1(),
+		MicroF1:         confusionMatrix.MicroF1(),
+		ConfusionMatrix: confusionMatrix}
+}
+
+//JSON returns the report in JSON format
+func (r EvaluationReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, " ", " ")
+}
+
+//MarshalJSON encodes the report, with undefined metrics (NaN or infinite, such as R2
+//when all the labels are equal) encoded as null, since JSON has no representation for them
+func (r EvaluationReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Examples        int
+		RMSE            *float64
+		MAE             *float64
+		R2              *float64
+		Accuracy        *float64
+		MacroF1         *float64
+		MicroF1         *float64
+		ConfusionMatrix MultiConfusionMatrix
+	}{r.Examples, definedMetric(r.RMSE), definedMetric(r.MAE), definedMetric(r.R2), definedMetric(r.Accuracy),
+		definedMetric(r.MacroF1), definedMetric(r.MicroF1), r.ConfusionMatrix})
+}
+
+//definedMetric returns a pointer to the metric value, or nil if it is NaN or infinite
+func definedMetric(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
+
+//RegressionReport holds the regression metrics of a model on a dataset
+type RegressionReport struct {
+	MSE  float64
+	RMSE float64
+	MAE  float64
+	R2   float64
+}
+
+//RegressionMetrics computes the regression metrics of the model on the dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+func RegressionMetrics(model Model, data []Example) RegressionReport {
+
+	report := RegressionReport{RMSE: RMSE(model, data), MAE: MAE(model, data), R2: R2(model, data)}
+	report.MSE = report.RMSE * report.RMSE
+	return report
+}
+
+//TestStream computes the regression metrics of the model on a CSV dataset read from the provided reader
+//(see ReadCSVStream), one example at a time, so the dataset is never held in memory.
+func TestStream(model Model, r io.Reader, options CSVOptions) (RegressionReport, LoadStats, error) {
+
+	sumError, sumAbsError, sumLabel, sumSquaredLabel := 0.0, 0.0, 0.0, 0.0
+	stats, err := ReadCSVStream(r, options, func(example Example) error {
+		if example.Unlabeled {
+			return ErrUnlabeled
+		}
+		error := Predict(model, normalizeExample(model, example)) - example.Label
+		sumError += error * error
+		sumAbsError += math.Abs(error)
+		sumLabel += example.Label
+		sumSquaredLabel += example.Label * example.Label
+		return nil
+	})
+	if err != nil {
+		return RegressionReport{}, stats, err
+	}
+	if stats.Examples == 0 {
+		return RegressionReport{}, stats, ErrEmptyDataset
+	}
+
+	n := float64(stats.Examples)
+	sumVariance := sumSquaredLabel - sumLabel*sumLabel/n
+	report := RegressionReport{MSE: sumError / n, MAE: sumAbsError / n, R2: 1 - sumError/sumVariance}
+	report.RMSE = math.Sqrt(report.MSE)
+	return report, stats, nil
+}
@@ -0,0 +1,142 @@
+package ml
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateAll(t *testing.T) {
+
+	data := informativeDataset(200, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 20})
+	names := []string{"rmse", "mae", "r2", "accuracy", "macro_f1"}
+
+	results, err := EvaluateAll(model, data, names)
+	if err != nil {
+		t.Fatalf("error evaluating model: %v", err)
+	}
+	if len(results) != len(names) {
+		t.Fatalf("expected %d metrics, found %v", len(names), results)
+	}
+	for _, name := range names {
+		if expected := Metrics[name](model, data); results[name] != expected {
+			t.Fatalf("%s: expected %v, found %v", name, expected, results[name])
+		}
+	}
+	if results["rmse"] != RMSE(model, data) || results["accuracy"] != Accuracy(model, data) {
+		t.Fatalf("expected the registered metrics to match the metric functions")
+	}
+
+	if _, err := EvaluateAll(model, data, []string{"rmse", "auc"}); err == nil {
+		t.Fatalf("expected an error for an unknown metric")
+	}
+}
+
+func TestReport(t *testing.T) {
+
+	data := informativeDataset(100, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 20})
+
+	report := Report(model, data)
+	matrix := NewMultiConfusionMatrix(model, data)
+	if report.Examples != 100 || report.RMSE != RMSE(model, data) || report.MAE != MAE(model, data) ||
+		report.R2 != R2(model, data) || report.Accuracy != Accuracy(model, data) ||
+		report.MacroF1 != matrix.MacroF1() || report.MicroF1 != matrix.MicroF1() ||
+		!equalFloats(report.ConfusionMatrix.Classes, matrix.Classes) {
+		t.Fatalf("expected the report to match the individual metrics, found %+v", report)
+	}
+
+	encoded, err := report.JSON()
+	if err != nil {
+		t.Fatalf("error encoding report: %v", err)
+	}
+	var decoded EvaluationReport
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("error decoding report: %v", err)
+	}
+	if decoded.Examples != report.Examples || decoded.RMSE != report.RMSE || decoded.MicroF1 != report.MicroF1 {
+		t.Fatalf("expected %+v, found %+v", report, decoded)
+	}
+}
+
+func TestRegressionMetrics(t *testing.T) {
+
+	data := GenerateSyntheticDataset(500, 3, 0.1, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 100})
+
+	report := RegressionMetrics(model, data)
+	if report.R2 < 0.99 || report.R2 > 1 {
+		t.Fatalf("expected R2 near 1, found %v", report.R2)
+	}
+	if math.Abs(report.MSE-report.RMSE*report.RMSE) > 1e-12 || report.MAE > report.RMSE || report.RMSE > 0.2 {
+		t.Fatalf("expected small and consistent errors, found %+v", report)
+	}
+}
+
+func TestTestStreamMatchesInMemoryMetrics(t *testing.T) {
+
+	fileName := writeTestFile(t, "test.csv", testCSV(testCSVHeader, 30))
+	data, err := ReadCSVDataSet(fileName)
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 10})
+	expected := RegressionMetrics(model, data)
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("error opening dataset: %v", err)
+	}
+	defer file.Close()
+	report, stats, err := TestStream(model, file, DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("error evaluating stream: %v", err)
+	}
+
+	if stats.Examples != 30 || math.Abs(report.MSE-expected.MSE) > 1e-9 || math.Abs(report.RMSE-expected.RMSE) > 1e-9 ||
+		math.Abs(report.MAE-expected.MAE) > 1e-9 || math.Abs(report.R2-expected.R2) > 1e-9 {
+		t.Fatalf("expected %+v over 30 examples, found %+v over %d", expected, report, stats.Examples)
+	}
+
+	if _, _, err := TestStream(model, strings.NewReader(testCSVHeader), DefaultCSVOptions()); !errors.Is(err, ErrEmptyDataset) {
+		t.Fatalf("expected ErrEmptyDataset for a header without rows, found %v", err)
+	}
+
+	options := DefaultCSVOptions()
+	options.Unlabeled = true
+	unlabeled := strings.NewReader("f0;f1;f2;f3;f4;f5;f6;f7;f8;f9\n1;2;3;4;5;6;7;8;9;10\n")
+	if _, _, err := TestStream(model, unlabeled, options); !errors.Is(err, ErrUnlabeled) {
+		t.Fatalf("expected ErrUnlabeled, found %v", err)
+	}
+}
+
+func TestReportJSONWithUndefinedMetrics(t *testing.T) {
+
+	//R2 is undefined when every label is the same
+	data := []Example{example(5, 1), example(5, 2), example(5, 3)}
+	model := Model{Bias: 5, Coeficients: []float64{0}, MinFeatureValues: []float64{0}, MaxFeatureValues: []float64{1}}
+
+	report := Report(model, data)
+	if !math.IsNaN(report.R2) {
+		t.Fatalf("expected an undefined R2, found %v", report.R2)
+	}
+	encoded, err := report.JSON()
+	if err != nil {
+		t.Fatalf("error encoding report: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("error decoding report: %v", err)
+	}
+	if r2, ok := decoded["R2"]; !ok || r2 != nil {
+		t.Fatalf("expected R2 to be null, found %v in %s", r2, encoded)
+	}
+	if decoded["Examples"] != 3.0 || decoded["RMSE"] != report.RMSE || decoded["Accuracy"] != report.Accuracy {
+		t.Fatalf("expected the defined metrics of %+v, found %s", report, encoded)
+	}
+}
@@ -1,14 +1,22 @@
 package ml
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"log"
 	"math"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 //Model is the Machine Learning model we are trying to learn
@@ -19,97 +27,625 @@ type Model struct {
 	Coeficients      []float64
 	MinFeatureValues []float64
 	MaxFeatureValues []float64
+	//Threshold is the probability from which models trained with a classification loss
+	//predict the positive class (see PredictClass). If zero, DefaultThreshold is used.
+	Threshold float64 `json:",omitempty"`
+	//Fingerprint identifies the model parameters (see ComputeFingerprint), it is set by SaveModel
+	Fingerprint string `json:",omitempty"`
 }
 
 //Example is a single data point consisting of a feature set and a label
 type Example struct {
 	Features []float64
 	Label    float64
+	//Unlabeled examples have no label (Label is NaN): they can be used for prediction,
+	//but not for training or testing
+	Unlabeled bool
+}
+
+//CSVOptions controls how a CSV dataset is read
+type CSVOptions struct {
+	//Decompressor decompresses the file contents. If nil, one is selected
+	//based on the file extension (see DecompressorForFile)
+	Decompressor Decompressor
+	//HeaderRows is the number of header lines skipped before the data.
+	//If zero, a single header line is skipped.
+	HeaderRows int
+	//NoHeader tells the data starts on the first line, HeaderRows is ignored
+	NoHeader bool
+	//MinFeatures and MaxFeatures, if not zero, cause rows with fewer or more features
+	//to be skipped instead of failing the load
+	MinFeatures int
+	MaxFeatures int
+	//PairFeatures adds the product of every pair of features as an extra feature.
+	//The number of features grows quadratically with the number of columns.
+	PairFeatures bool
+	//LabelColumnName selects the label column by its name in the first header row.
+	//If empty, the last column is the label. All other columns are features.
+	LabelColumnName string
+	//Unlabeled reads every column as a feature, producing unlabeled examples
+	Unlabeled bool
+	//LabelMap, if not nil, is applied to every parsed label. It returns the new label,
+	//and whether to keep the example
+	LabelMap func(label float64) (float64, bool)
+}
+
+//LoadStats describes the outcome of loading a dataset
+type LoadStats struct {
+	//Examples is the number of examples loaded
+	Examples int
+	//DroppedFeatureCount is the number of rows skipped for having too few or too many features
+	DroppedFeatureCount int
+	//DroppedInvalidLabel is the number of rows skipped because their label is NaN or infinite
+	DroppedInvalidLabel int
+	//DroppedByLabelMap is the number of rows skipped by CSVOptions.LabelMap
+	DroppedByLabelMap int
+	//Header is the first header row, if any
+	Header []string
+}
+
+//DefaultCSVOptions returns the options used by ReadCSVDataSet: a single header row
+func DefaultCSVOptions() CSVOptions {
+	return CSVOptions{HeaderRows: 1}
+}
+
+//headerRows returns the number of header lines to skip
+func (o CSVOptions) headerRows() int {
+	if o.NoHeader {
+		return 0
+	}
+	if o.HeaderRows < 1 {
+		return 1
+	}
+	return o.HeaderRows
 }
 
 //ReadCSVDataSet reads a CSV dataset
 func ReadCSVDataSet(fileName string) ([]Example, error) {
+	dataSet, _, err := ReadCSVDataSetWithOptions(fileName, DefaultCSVOptions())
+	return dataSet, err
+}
+
+//ReadCSVDataSetWithOptions reads a CSV dataset, using the provided options
+func ReadCSVDataSetWithOptions(fileName string, options CSVOptions) ([]Example, LoadStats, error) {
+	stats := LoadStats{}
+	decompressor := options.Decompressor
+	if decompressor == nil {
+		var err error
+		decompressor, err = DecompressorForFile(fileName)
+		if err != nil {
+			return nil, stats, err
+		}
+	}
+
 	inputFile, err := os.Open(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file %s: %w", fileName, err)
+		return nil, stats, fmt.Errorf("error opening file %s: %w", fileName, err)
+	}
+	defer inputFile.Close()
+
+	options.Decompressor = decompressor
+	return ReadCSVDataSetFrom(inputFile, options)
+}
+
+//ReadCSVDataSetFrom reads a CSV dataset from the provided reader, using the provided options.
+//The input is decompressed only if options.Decompressor is set.
+func ReadCSVDataSetFrom(r io.Reader, options CSVOptions) ([]Example, LoadStats, error) {
+
+	dataSet := make([]Example, 0)
+	stats, err := ReadCSVStream(r, options, func(example Example) error {
+		dataSet = append(dataSet, example)
+		return nil
+	})
+	if err != nil {
+		return nil, stats, err
+	}
+	return dataSet, stats, nil
+}
+
+//ReadCSVStream reads a CSV dataset from the provided reader as ReadCSVDataSetFrom does,
+//calling fn for each example as it is read instead of keeping the whole dataset in memory.
+//Reading stops at the first error returned by fn.
+func ReadCSVStream(r io.Reader, options CSVOptions, fn func(Example) error) (LoadStats, error) {
+	stats := LoadStats{}
+	input := r
+	var err error
+	if options.Decompressor != nil {
+		input, err = options.Decompressor(r)
+		if err != nil {
+			return stats, fmt.Errorf("error decompressing input: %w", err)
+		}
 	}
-	reader := csv.NewReader(inputFile)
+	//csv.Reader already drops the \r of CRLF line endings,
+	//but a UTF-8 BOM would end up in the first field
+	reader := csv.NewReader(skipBOM(input))
 	reader.Comma = ';'
+	filterFeatureCount := options.MinFeatures > 0 || options.MaxFeatures > 0
+	if filterFeatureCount {
+		//Rows are allowed to have different lengths, the filter takes care of them
+		reader.FieldsPerRecord = -1
+	}
 	var example Example
-	//Ignore the first line (header)
-	reader.Read()
-	record, err := reader.Read()
+	var record []string
+	headerRows := options.headerRows()
+	if options.LabelColumnName != "" && headerRows < 1 {
+		return stats, fmt.Errorf("a header row is required to find label column %s", options.LabelColumnName)
+	}
+	//labelColumn is the index of the label column, -1 for the last column
+	labelColumn := -1
+	for i := 0; i < headerRows && err == nil; i++ {
+		record, err = reader.Read()
+		if err == nil && i == 0 {
+			stats.Header = record
+		}
+		if err == nil && i == 0 && options.LabelColumnName != "" {
+			labelColumn = columnIndex(record, options.LabelColumnName)
+			if labelColumn < 0 {
+				return stats, fmt.Errorf("label column %s not found in header", options.LabelColumnName)
+			}
+		}
+	}
+	if err == nil {
+		record, err = reader.Read()
+	}
 
-	dataSet := make([]Example, 0)
 	for err == nil {
 
-		if len(record) < 10 {
-			return nil, fmt.Errorf("error: expected 10 values, found %d", len(record))
+		numFeatures := len(record) - 1
+		if options.Unlabeled {
+			numFeatures = len(record)
+		}
+		if filterFeatureCount && (numFeatures < options.MinFeatures ||
+			(options.MaxFeatures > 0 && numFeatures > options.MaxFeatures)) {
+			stats.DroppedFeatureCount++
+			record, err = reader.Read()
+			continue
+		}
+
+		//Without a feature count filter, rows are expected to have at least 10 values
+		if !filterFeatureCount && !options.Unlabeled && len(record) < 10 {
+			return stats, fmt.Errorf("%w: expected 10 values, found %d", ErrFeatureCountMismatch, len(record))
 
 		}
-		example = Example{Features: make([]float64, len(record)-1)}
+		//labelIndex is -1 for unlabeled examples
+		labelIndex := -1
+		if !options.Unlabeled {
+			labelIndex = len(record) - 1
+			if labelColumn >= 0 {
+				labelIndex = labelColumn
+			}
+			if labelIndex >= len(record) {
+				return stats, fmt.Errorf("%w: label column %d not found, found %d values", ErrFeatureCountMismatch, labelIndex, len(record))
+			}
+		}
+		example = Example{Features: make([]float64, 0, numFeatures)}
 
-		for i := 0; i < len(example.Features); i++ {
-			example.Features[i], err = strconv.ParseFloat(record[i], 64)
+		for i := 0; i < len(record); i++ {
+			if i == labelIndex {
+				continue
+			}
+			feature, err := strconv.ParseFloat(record[i], 64)
 			if err != nil {
-				return nil, fmt.Errorf("Error parsing feature value (%s): %w ", record[i], err)
+				return stats, newCauseError(ErrFeatureParse, err, fmt.Sprintf("%v (%s): %v", ErrFeatureParse, record[i], err))
 
 			}
+			example.Features = append(example.Features, feature)
 
 		}
-		example.Label, err = strconv.ParseFloat(record[len(record)-1], 64)
+		if options.Unlabeled {
+			example.Label = math.NaN()
+			example.Unlabeled = true
+			if options.PairFeatures {
+				example.Features = AddPairFeatures(example.Features)
+			}
+			stats.Examples++
+			if err := fn(example); err != nil {
+				return stats, err
+			}
+			record, err = reader.Read()
+			continue
+		}
+		example.Label, err = strconv.ParseFloat(record[labelIndex], 64)
 
-		if err != nil {
-			return nil, fmt.Errorf("error parsing label (%s): %w", record[len(record)-1], err)
+		//Out of range values are parsed as infinite, and skipped below
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return stats, newCauseError(ErrLabelParse, err, fmt.Sprintf("%v (%s): %v", ErrLabelParse, record[labelIndex], err))
+		}
+		if math.IsNaN(example.Label) || math.IsInf(example.Label, 0) {
+			stats.DroppedInvalidLabel++
+			record, err = reader.Read()
+			continue
+		}
+		if options.LabelMap != nil {
+			var keep bool
+			example.Label, keep = options.LabelMap(example.Label)
+			if !keep {
+				stats.DroppedByLabelMap++
+				record, err = reader.Read()
+				continue
+			}
+		}
+		if options.PairFeatures {
+			example.Features = AddPairFeatures(example.Features)
+		}
+		stats.Examples++
+		if err := fn(example); err != nil {
+			return stats, err
 		}
-		dataSet = append(dataSet, example)
 
 		record, err = reader.Read()
 	}
 
 	if err != io.EOF {
-		return nil, fmt.Errorf("Error: %s", err)
+		return stats, fmt.Errorf("Error: %s", err)
+
+	}
+	return stats, nil
+}
+
+//ReadCSVDataSets reads several CSV files as a single dataset, skipping the header rows of each file.
+//All files must have the same header, so their columns are known to match.
+//The returned stats are aggregated over all files.
+func ReadCSVDataSets(fileNames []string, options CSVOptions) ([]Example, LoadStats, error) {
+
+	dataSet := make([]Example, 0)
+	stats := LoadStats{}
+	for _, fileName := range fileNames {
+		fileDataSet, fileStats, err := ReadCSVDataSetWithOptions(fileName, options)
+		if err != nil {
+			return nil, stats, fmt.Errorf("error reading %s: %w", fileName, err)
+		}
+		if stats.Header == nil {
+			stats.Header = fileStats.Header
+		} else if !equalStrings(stats.Header, fileStats.Header) {
+			return nil, stats, fmt.Errorf("%w: header of %s does not match the header of %s",
+				ErrHeaderMismatch, fileName, fileNames[0])
+		}
+		dataSet = append(dataSet, fileDataSet...)
+		stats.Examples += fileStats.Examples
+		stats.DroppedFeatureCount += fileStats.DroppedFeatureCount
+		stats.DroppedInvalidLabel += fileStats.DroppedInvalidLabel
+		stats.DroppedByLabelMap += fileStats.DroppedByLabelMap
+	}
+
+	return dataSet, stats, nil
+}
+
+//ReadGlobDataSet reads the CSV files matching a glob pattern (see filepath.Match) as a single dataset,
+//in lexical order, as ReadCSVDataSets does. Compressed files are decompressed based on their extension,
+//unless options.Decompressor is set.
+func ReadGlobDataSet(pattern string, options CSVOptions) ([]Example, LoadStats, error) {
+
+	fileNames, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, LoadStats{}, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	if len(fileNames) == 0 {
+		return nil, LoadStats{}, fmt.Errorf("no files match %s", pattern)
+	}
+
+	return ReadCSVDataSets(fileNames, options)
+}
+
+//DatasetType tells what kind of values the features of a dataset hold
+type DatasetType int
+
+const (
+	//NumericDataset features are all numbers, and can be read with ReadCSVDataSet
+	NumericDataset DatasetType = iota
+	//TextDataset features contain non numeric values, which ReadCSVDataSet can not read
+	TextDataset
+)
+
+//datasetTypeSampleRows is the number of rows DetectDatasetType looks at
+const datasetTypeSampleRows = 100
+
+//DetectDatasetType samples the first rows of a CSV dataset (after a header row)
+//and reports whether its features are numeric or textual
+func DetectDatasetType(fileName string) (DatasetType, error) {
+
+	decompressor, err := DecompressorForFile(fileName)
+	if err != nil {
+		return NumericDataset, err
+	}
+	inputFile, err := os.Open(fileName)
+	if err != nil {
+		return NumericDataset, fmt.Errorf("error opening file %s: %w", fileName, err)
+	}
+	defer inputFile.Close()
+	input, err := decompressor(inputFile)
+	if err != nil {
+		return NumericDataset, fmt.Errorf("error decompressing file %s: %w", fileName, err)
+	}
 
+	reader := csv.NewReader(skipBOM(input))
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+	reader.Read()
+	for i := 0; i < datasetTypeSampleRows; i++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return NumericDataset, err
+		}
+		//The last column is the label
+		for _, field := range record[:len(record)-1] {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+				return TextDataset, nil
+			}
+		}
 	}
-	return dataSet, nil
+
+	return NumericDataset, nil
+}
+
+//EpochStats describes a completed training epoch
+type EpochStats struct {
+	Epoch int
+	//Loss is the RMSE over the training set during the epoch
+	Loss float64
+	//Duration is the wall-clock time the epoch took
+	Duration time.Duration
+	//ETA is the estimated time left to finish training, based on the average epoch duration so far
+	ETA time.Duration
+	//LossValue is the average value of the training loss function over the epoch
+	LossValue float64
+	//GradientNorm is the L2 norm of the average gradient (bias included) over the epoch
+	GradientNorm float64
+	//LearningRate is the learning rate used during the epoch
+	LearningRate float64
+	//Warning, if not empty, describes a training problem detected during the epoch
+	Warning string
+}
+
+//EpochListener is notified at the end of every training epoch
+type EpochListener func(EpochStats)
+
+//TrainOptions controls the training loop
+type TrainOptions struct {
+	LearningRate float64
+	NumEpochs    int
+	//MaxDuration, if not zero, stops training once it has elapsed.
+	//The epoch running when the budget runs out is completed.
+	MaxDuration time.Duration
+	//GradientTolerance, if not zero, stops training after an epoch
+	//whose gradient norm is below it
+	GradientTolerance float64
+	//Loss is the loss function to minimize, SquaredError if nil
+	Loss Loss
+	//AutoLearningRate halves the learning rate when the loss keeps increasing,
+	//a sign the learning rate is too high
+	AutoLearningRate bool
+	//Initializer sets the initial coefficients, ZeroInitializer if nil
+	Initializer Initializer
+	//Seed is the seed of the random numbers used by the Initializer
+	Seed int64
+	//EpochListener, if not nil, is called at the end of every epoch
+	EpochListener EpochListener
+	//LogEvery, if greater than 1, calls EpochListener only every LogEvery epochs
+	//(and at the end of the last epoch)
+	LogEvery int
 }
 
 //Train executes the training loop
 func Train(dataSet []Example, learningRate float64, numEpochs int) (Model, error) {
+	model, _, err := TrainWithOptions(dataSet, TrainOptions{LearningRate: learningRate, NumEpochs: numEpochs,
+		EpochListener: PrintEpochStats})
+	return model, err
+}
+
+//PrintEpochStats is an EpochListener printing the epoch loss and warning to the standard output
+func PrintEpochStats(stats EpochStats) {
+	fmt.Printf("Epoch %d error %.3f\n", stats.Epoch, stats.Loss)
+	if stats.Warning != "" {
+		fmt.Printf("Warning: %s\n", stats.Warning)
+	}
+}
+
+//divergingEpochs is the number of consecutive loss increases after which training is considered diverging
+const divergingEpochs = 3
+
+//TrainWithOptions executes the training loop with the provided options.
+//Returns the trained model and the number of epochs actually run.
+func TrainWithOptions(dataSet []Example, options TrainOptions) (Model, int, error) {
+	return TrainContext(context.Background(), dataSet, options)
+}
+
+//TrainContext executes the training loop with the provided options until the context is cancelled.
+//As with MaxDuration, the epoch running when the context is cancelled is completed, and the model
+//trained so far is returned without error: callers can check ctx.Err() to tell whether training was interrupted.
+func TrainContext(ctx context.Context, dataSet []Example, options TrainOptions) (Model, int, error) {
+	model, _, epochs, err := train(ctx, dataSet, options, -1)
+	return model, epochs, err
+}
+
+//TrainSWA executes the training loop with the provided options, also computing the stochastic weight
+//average (SWA) model: the average of the models at the end of every epoch from swaStartEpoch on
+//(counted from 0, as in EpochStats). Averaging the last epochs usually generalizes better than the final model.
+//Returns the final model, the SWA model and the number of epochs actually run.
+//If training stops before swaStartEpoch, the SWA model is the final model.
+func TrainSWA(dataSet []Example, options TrainOptions, swaStartEpoch int) (Model, Model, int, error) {
+	if swaStartEpoch < 0 {
+		swaStartEpoch = 0
+	}
+	return train(context.Background(), dataSet, options, swaStartEpoch)
+}
+
+//train executes the training loop, averaging the models from swaStartEpoch on if it is not negative
+func train(ctx context.Context, dataSet []Example, options TrainOptions, swaStartEpoch int) (Model, Model, int, error) {
 
 	//Assumes the dataset has been normalized
 
+	if err := checkLabeled(dataSet); err != nil {
+		return Model{}, Model{}, 0, err
+	}
+
 	min, max, err := NormalizeDataSetFeatures(dataSet)
 
 	if err != nil {
-		return Model{}, fmt.Errorf("error normalizing dataset: %w", err)
+		return Model{}, Model{}, 0, fmt.Errorf("error normalizing dataset: %w", err)
 	}
 	model := Model{Coeficients: make([]float64, len(dataSet[0].Features)),
 		MinFeatureValues: min, MaxFeatureValues: max}
+	if options.Initializer != nil {
+		options.Initializer(model.Coeficients, rand.New(rand.NewSource(options.Seed)))
+	}
 
-	for epoch := 0; epoch < numEpochs; epoch++ {
-
-		sumError := 0.0
-		for i := 0; i < len(dataSet); i++ {
-			prediction := Predict(model, dataSet[i])
-			error := prediction - dataSet[i].Label
-			sumError += error * error
-			model.Bias -= learningRate * error
+	learningRate := options.LearningRate
+	loss := options.Loss
+	if loss == nil {
+		loss = SquaredError{}
+	}
+	trainingStart := time.Now()
+	//previousLoss and lossIncreases track consecutive increases of the loss
+	previousLoss := math.Inf(1)
+	lossIncreases := 0
+	//swa is the running average of the models since swaStartEpoch, over swaEpochs epochs
+	swa := Model{}
+	swaEpochs := 0
+
+	epoch := 0
+	for ; epoch < options.NumEpochs; epoch++ {
+
+		epochStart := time.Now()
+		sumError, sumLoss, gradient := runEpoch(&model, dataSet, learningRate, loss)
+
+		rmse := math.Sqrt(sumError / float64(len(dataSet)))
+		gradientNorm := 0.0
+		for _, g := range gradient {
+			gradientNorm += g * g
+		}
+		gradientNorm = math.Sqrt(gradientNorm) / float64(len(dataSet))
+		elapsed := time.Since(trainingStart)
+
+		if swaStartEpoch >= 0 && epoch >= swaStartEpoch {
+			if swaEpochs == 0 {
+				swa = model.Clone()
+			} else {
+				swa.Bias += (model.Bias - swa.Bias) / float64(swaEpochs+1)
+				for j := range swa.Coeficients {
+					swa.Coeficients[j] += (model.Coeficients[j] - swa.Coeficients[j]) / float64(swaEpochs+1)
+				}
+			}
+			swaEpochs++
+		}
 
-			for j := 0; j < len(model.Coeficients); j++ {
-				model.Coeficients[j] -= learningRate * error * dataSet[i].Features[j]
+		lossValue := sumLoss / float64(len(dataSet))
+		epochLearningRate := learningRate
+		warning := ""
+		//A loss overflowing to infinity or NaN is the worst kind of increase
+		if lossValue > previousLoss || math.IsNaN(lossValue) || math.IsInf(lossValue, 1) {
+			lossIncreases++
+		} else {
+			lossIncreases = 0
+		}
+		previousLoss = lossValue
+		if lossIncreases >= divergingEpochs {
+			warning = fmt.Sprintf("loss increased for %d epochs, the learning rate (%g) is probably too high",
+				lossIncreases, learningRate)
+			if options.AutoLearningRate {
+				learningRate /= 2
+				lossIncreases = 0
+				warning += fmt.Sprintf(", reducing it to %g", learningRate)
+			}
+		}
 
+		lastEpoch := epoch == options.NumEpochs-1 ||
+			(options.MaxDuration > 0 && elapsed >= options.MaxDuration) ||
+			gradientNorm < options.GradientTolerance ||
+			ctx.Err() != nil
+
+		if options.EpochListener != nil && (options.LogEvery <= 1 || epoch%options.LogEvery == 0 || lastEpoch || warning != "") {
+			averageEpoch := elapsed / time.Duration(epoch+1)
+			eta := averageEpoch * time.Duration(options.NumEpochs-epoch-1)
+			if options.MaxDuration > 0 && options.MaxDuration-elapsed < eta {
+				eta = options.MaxDuration - elapsed
+				if eta < 0 {
+					eta = 0
+				}
 			}
+			options.EpochListener(EpochStats{Epoch: epoch, Loss: rmse,
+				LossValue:    lossValue,
+				Duration:     time.Since(epochStart),
+				ETA:          eta,
+				GradientNorm: gradientNorm,
+				LearningRate: epochLearningRate,
+				Warning:      warning})
+		}
 
+		if lastEpoch {
+			epoch++
+			break
 		}
 
-		loss := math.Sqrt(sumError / float64(len(dataSet)))
-		fmt.Printf("Epoch %d error %.3f\n", epoch, loss)
+	}
 
+	if swaEpochs == 0 {
+		swa = model.Clone()
 	}
+	return model, swa, epoch, nil
 
-	return model, nil
+}
+
+//runEpoch makes a stochastic gradient descent pass over the normalized dataset.
+//Returns the sum of the squared errors, the sum of the loss values, and the sum of the gradients
+//(the bias gradient being the last element)
+func runEpoch(model *Model, dataSet []Example, learningRate float64, loss Loss) (float64, float64, []float64) {
+
+	sumError := 0.0
+	sumLoss := 0.0
+	gradient := make([]float64, len(model.Coeficients)+1)
+	for i := 0; i < len(dataSet); i++ {
+		prediction := Predict(*model, dataSet[i])
+		error := prediction - dataSet[i].Label
+		sumError += error * error
+		sumLoss += loss.Value(prediction, dataSet[i].Label)
+		lossGradient := loss.Gradient(prediction, dataSet[i].Label)
+		model.Bias -= learningRate * lossGradient
+		gradient[len(model.Coeficients)] += lossGradient
+
+		for j := 0; j < len(model.Coeficients); j++ {
+			model.Coeficients[j] -= learningRate * lossGradient * dataSet[i].Features[j]
+			gradient[j] += lossGradient * dataSet[i].Features[j]
+
+		}
+
+	}
 
+	return sumError, sumLoss, gradient
+}
+
+//PartialFit makes a single stochastic gradient descent pass over a batch, updating the model in place,
+//so callers can train on datasets that do not fit in memory.
+//On the first call (a model without coefficients) the normalization limits are taken from the batch;
+//later batches are normalized in place with those limits.
+func PartialFit(model *Model, batch []Example, learningRate float64) error {
+
+	if err := checkLabeled(batch); err != nil {
+		return err
+	}
+
+	if len(model.Coeficients) == 0 {
+		min, max, err := NormalizeDataSetFeatures(batch)
+		if err != nil {
+			return fmt.Errorf("error normalizing dataset: %w", err)
+		}
+		model.Coeficients = make([]float64, len(batch[0].Features))
+		model.MinFeatureValues, model.MaxFeatureValues = min, max
+	} else {
+		for i, example := range batch {
+			if len(example.Features) != len(model.Coeficients) {
+				return fmt.Errorf("%w: example %d: expected %d features, found %d",
+					ErrFeatureCountMismatch, i, len(model.Coeficients), len(example.Features))
+			}
+		}
+		NormalizeDatasetFeaturesWithLimits(batch, model.MaxFeatureValues, model.MinFeatureValues)
+	}
+
+	runEpoch(model, batch, learningRate, SquaredError{})
+	return nil
 }
 
 //NormalizeDataSetFeatures normalize the features in the dataset
@@ -118,7 +654,7 @@ func Train(dataSet []Example, learningRate float64, numEpochs int) (Model, error
 func NormalizeDataSetFeatures(dataSet []Example) ([]float64, []float64, error) {
 
 	if len(dataSet) < 1 {
-		return nil, nil, fmt.Errorf("empty data set")
+		return nil, nil, ErrEmptyDataset
 	}
 	//Assumes all examples have the same number of features
 	maxValues := make([]float64, len(dataSet[0].Features))
@@ -134,7 +670,7 @@ func NormalizeDataSetFeatures(dataSet []Example) ([]float64, []float64, error) {
 	//Find min and max
 	for i := 0; i < len(dataSet); i++ {
 		if len(dataSet[i].Features) < len(maxValues) {
-			return nil, nil, fmt.Errorf("expected %d features, found %d ", len(maxValues), len(dataSet[i].Features))
+			return nil, nil, fmt.Errorf("%w: expected %d features, found %d", ErrFeatureCountMismatch, len(maxValues), len(dataSet[i].Features))
 		}
 		for j := 0; j < len(maxValues); j++ {
 			maxValues[j] = math.Max(maxValues[j], dataSet[i].Features[j])
@@ -176,35 +712,173 @@ func Predict(model Model, example Example) float64 {
 //SaveModel saves a model to a file in JSON format.
 func SaveModel(model Model, fileName string) error {
 
-	content, err := json.MarshalIndent(model, " ", " ")
+	outputFile, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = SaveModelTo(outputFile, model)
 	if err != nil {
+		outputFile.Close()
 		return err
 	}
 
-	return ioutil.WriteFile(fileName, content, 0644)
+	return outputFile.Close()
+
+}
 
+//SaveModelTo writes a model in JSON format to the provided writer,
+//streaming it instead of building the whole document in memory.
+func SaveModelTo(w io.Writer, model Model) error {
+	return SaveModelToContext(context.Background(), w, model)
+}
+
+//SaveModelToContext writes a model in JSON format to the provided writer.
+//The model is written piece by piece, and the write is aborted if the context is cancelled.
+func SaveModelToContext(ctx context.Context, w io.Writer, model Model) error {
+
+	writer := bufio.NewWriter(contextWriter{ctx: ctx, w: w})
+	model.Fingerprint = model.ComputeFingerprint()
+
+	//The layout is the one of json.MarshalIndent with a " " prefix and indent
+	bias, err := json.Marshal(model.Bias)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "{\n  \"Bias\": %s,\n", bias)
+
+	arrays := []struct {
+		name   string
+		values []float64
+	}{{"Coeficients", model.Coeficients},
+		{"MinFeatureValues", model.MinFeatureValues},
+		{"MaxFeatureValues", model.MaxFeatureValues}}
+	for _, array := range arrays {
+		if err := writeJSONArray(ctx, writer, array.name, array.values); err != nil {
+			return err
+		}
+	}
+
+	if model.Threshold != 0 {
+		threshold, err := json.Marshal(model.Threshold)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "  \"Threshold\": %s,\n", threshold)
+	}
+
+	fingerprint, err := json.Marshal(model.Fingerprint)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "  \"Fingerprint\": %s\n }\n", fingerprint)
+
+	return writer.Flush()
+
+}
+
+//writeJSONArray writes a field holding an array of numbers, checking the context between values
+func writeJSONArray(ctx context.Context, writer *bufio.Writer, name string, values []float64) error {
+
+	fmt.Fprintf(writer, "  %q: ", name)
+	switch {
+	case values == nil:
+		writer.WriteString("null")
+	case len(values) == 0:
+		writer.WriteString("[]")
+	default:
+		writer.WriteString("[\n")
+		for i, value := range values {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				writer.WriteString(",\n")
+			}
+			writer.WriteString("   ")
+			writer.Write(encoded)
+		}
+		writer.WriteString("\n  ]")
+	}
+	_, err := writer.WriteString(",\n")
+	return err
+}
+
+//contextWriter fails writes once its context is done
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
 }
 
 //LoadModel loads a model from a file
 func LoadModel(fileName string) (Model, error) {
 
+	inputFile, err := os.Open(fileName)
+	if err != nil {
+		return Model{}, err
+	}
+	defer inputFile.Close()
+
+	return LoadModelFrom(inputFile)
+
+}
+
+//LoadModelFrom loads a model in JSON format from the provided reader
+func LoadModelFrom(r io.Reader) (Model, error) {
+
 	model := Model{}
-	content, err := ioutil.ReadFile(fileName)
+	err := json.NewDecoder(r).Decode(&model)
 	if err != nil {
-		return model, err
+		return Model{}, newCauseError(ErrModelCorrupt, err, fmt.Sprintf("%v: %v", ErrModelCorrupt, err))
 	}
 
-	err = json.Unmarshal(content, &model)
+	if len(model.Coeficients) == 0 {
+		return Model{}, fmt.Errorf("%w: no coefficients found", ErrModelCorrupt)
+	}
+	if model.MinFeatureValues == nil && model.MaxFeatureValues == nil {
+		//Models saved before normalization was introduced only have the bias and the coefficients:
+		//they were trained on raw features, which a 0-1 range leaves unchanged
+		log.Printf("model has no feature limits, assuming features are not normalized")
+		model.MinFeatureValues = make([]float64, len(model.Coeficients))
+		model.MaxFeatureValues = make([]float64, len(model.Coeficients))
+		for i := range model.MaxFeatureValues {
+			model.MaxFeatureValues[i] = 1
+		}
+	}
+	if len(model.MinFeatureValues) != len(model.Coeficients) || len(model.MaxFeatureValues) != len(model.Coeficients) {
+		return Model{}, fmt.Errorf("%w: expected %d feature limits, found %d minimum and %d maximum values",
+			ErrModelCorrupt, len(model.Coeficients), len(model.MinFeatureValues), len(model.MaxFeatureValues))
+	}
+	if model.Fingerprint != "" && model.Fingerprint != model.ComputeFingerprint() {
+		log.Printf("model fingerprint %s does not match its parameters, the model may have been modified or corrupted",
+			model.Fingerprint)
+	}
 
-	return model, err
+	return model, nil
 
 }
 
 type testListener func(Example, float64)
 
-//Test tests the provided model in the provided dataset, returning the loss
+//Test tests the provided model in the provided dataset, returning the loss.
+//The loss is NaN if the dataset contains unlabeled examples.
 func Test(model Model, dataSet []Example, listener testListener) float64 {
 
+	if checkLabeled(dataSet) != nil {
+		return math.NaN()
+	}
+
 	NormalizeDatasetFeaturesWithLimits(dataSet, model.MaxFeatureValues, model.MinFeatureValues)
 	//Assuming loss is RMSE
 
@@ -220,3 +894,44 @@ func Test(model Model, dataSet []Example, listener testListener) float64 {
 	return math.Sqrt(sumError / float64(len(dataSet)))
 
 }
+
+//checkLabeled returns an error if the dataset contains unlabeled examples
+func checkLabeled(dataSet []Example) error {
+	for i, example := range dataSet {
+		if example.Unlabeled {
+			return fmt.Errorf("%w: example %d", ErrUnlabeled, i)
+		}
+	}
+	return nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.TrimSpace(a[i]) != strings.TrimSpace(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//columnIndex returns the index of the named column in a header row, or -1 if not found
+func columnIndex(header []string, name string) int {
+	for i, column := range header {
+		if strings.TrimSpace(column) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+//skipBOM returns a reader that skips the UTF-8 byte order mark at the start of r, if present
+func skipBOM(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		buffered.Discard(3)
+	}
+	return buffered
+}
@@ -0,0 +1,664 @@
+package ml
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+//go:embed testdata/white.model
+var testModels embed.FS
+
+//testCSVHeader is the header of the CSV files written by testCSV
+const testCSVHeader = "f0;f1;f2;f3;f4;f5;f6;f7;f8;f9;quality\n"
+
+//testCSV returns a CSV dataset with the provided header lines followed by rows
+//of 10 features, where feature j of row i is i+j, and label i%3
+func testCSV(header string, rows int) string {
+	var builder strings.Builder
+	builder.WriteString(header)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&builder, "%d;", i+j)
+		}
+		fmt.Fprintf(&builder, "%d\n", i%3)
+	}
+	return builder.String()
+}
+
+func TestSaveModelToRoundTrip(t *testing.T) {
+
+	model := Model{Bias: 0.5, Coeficients: []float64{1, -2.25, 0, 1e-9},
+		MinFeatureValues: []float64{0, 1, 2, 3}, MaxFeatureValues: []float64{1, 2, 3, 4}}
+
+	var buffer bytes.Buffer
+	if err := SaveModelTo(&buffer, model); err != nil {
+		t.Fatalf("error saving model: %v", err)
+	}
+	loaded, err := LoadModelFrom(&buffer)
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+
+	if loaded.Bias != model.Bias || !equalFloats(loaded.Coeficients, model.Coeficients) ||
+		!equalFloats(loaded.MinFeatureValues, model.MinFeatureValues) ||
+		!equalFloats(loaded.MaxFeatureValues, model.MaxFeatureValues) {
+		t.Fatalf("expected %v, loaded %v", model, loaded)
+	}
+}
+
+//cancellingWriter cancels its context after the first write
+type cancellingWriter struct {
+	cancel context.CancelFunc
+	writes int
+}
+
+func (w *cancellingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	w.cancel()
+	return len(p), nil
+}
+
+func TestSaveModelToContextCancelled(t *testing.T) {
+
+	model := Model{Coeficients: make([]float64, 200000),
+		MinFeatureValues: make([]float64, 200000), MaxFeatureValues: make([]float64, 200000)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	writer := &cancellingWriter{cancel: cancel}
+	err := SaveModelToContext(ctx, writer, model)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, found %v", err)
+	}
+	if writer.writes != 1 {
+		t.Fatalf("expected the model to stop being written after the first write, found %d writes", writer.writes)
+	}
+}
+
+func TestReadCSVDataSetHeaderRows(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		header  string
+		options CSVOptions
+	}{
+		{"no header", "", CSVOptions{NoHeader: true}},
+		{"one header row", testCSVHeader, CSVOptions{HeaderRows: 1}},
+		{"two header rows", testCSVHeader + testCSVHeader, CSVOptions{HeaderRows: 2}},
+		{"default header row", testCSVHeader, CSVOptions{}},
+	}
+	for _, test := range tests {
+		dataSet, stats, err := ReadCSVDataSetFrom(strings.NewReader(testCSV(test.header, 5)), test.options)
+		if err != nil {
+			t.Fatalf("%s: error reading dataset: %v", test.name, err)
+		}
+		if len(dataSet) != 5 || stats.Examples != 5 {
+			t.Fatalf("%s: expected 5 examples, found %d", test.name, len(dataSet))
+		}
+		if dataSet[0].Features[0] != 0 {
+			t.Fatalf("%s: expected the first example to be the first data row, found %v", test.name, dataSet[0])
+		}
+	}
+}
+
+func TestLoadModelFrom(t *testing.T) {
+
+	expected, err := LoadModel("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+
+	content, err := os.ReadFile("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error reading model: %v", err)
+	}
+	model, err := LoadModelFrom(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("error loading model from a reader: %v", err)
+	}
+	if model.Bias != expected.Bias || !equalFloats(model.Coeficients, expected.Coeficients) {
+		t.Fatalf("expected %v, found %v", expected, model)
+	}
+
+	embedded, err := testModels.Open("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error opening embedded model: %v", err)
+	}
+	defer embedded.Close()
+	model, err = LoadModelFrom(embedded)
+	if err != nil {
+		t.Fatalf("error loading embedded model: %v", err)
+	}
+	if model.Bias != expected.Bias || !equalFloats(model.Coeficients, expected.Coeficients) ||
+		len(model.Coeficients) != 11 {
+		t.Fatalf("expected %v, found %v", expected, model)
+	}
+}
+
+func TestReadCSVDataSetBOMAndCRLF(t *testing.T) {
+
+	csv := "\xEF\xBB\xBF" + strings.ReplaceAll(testCSV(testCSVHeader, 3), "\n", "\r\n")
+	dataSet, stats, err := ReadCSVDataSetFrom(strings.NewReader(csv), DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+
+	if stats.Header[0] != "f0" || stats.Header[len(stats.Header)-1] != "quality" {
+		t.Fatalf("expected a clean header, found %q", stats.Header)
+	}
+	if len(dataSet) != 3 {
+		t.Fatalf("expected 3 examples, found %d", len(dataSet))
+	}
+	for i, example := range dataSet {
+		if example.Label != float64(i%3) || example.Features[0] != float64(i) {
+			t.Fatalf("example %d: unexpected values %v", i, example)
+		}
+	}
+}
+
+func TestEpochDurationAndETA(t *testing.T) {
+
+	const numEpochs = 5
+	stats := make([]EpochStats, 0, numEpochs)
+	_, _, err := TrainWithOptions(GenerateSyntheticDataset(2000, 5, 0.1, 1), TrainOptions{LearningRate: 0.001,
+		NumEpochs: numEpochs, EpochListener: func(epoch EpochStats) {
+			stats = append(stats, epoch)
+		}})
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+
+	if len(stats) != numEpochs {
+		t.Fatalf("expected %d epochs reported, found %d", numEpochs, len(stats))
+	}
+	for _, epoch := range stats {
+		if epoch.Duration <= 0 {
+			t.Fatalf("epoch %d: expected a positive duration, found %v", epoch.Epoch, epoch.Duration)
+		}
+		if epoch.ETA < 0 {
+			t.Fatalf("epoch %d: expected a non negative ETA, found %v", epoch.Epoch, epoch.ETA)
+		}
+	}
+	if stats[0].ETA <= stats[numEpochs-1].ETA || stats[numEpochs-1].ETA != 0 {
+		t.Fatalf("expected the ETA to decrease to 0, found %v then %v", stats[0].ETA, stats[numEpochs-1].ETA)
+	}
+}
+
+func TestReadCSVDataSetFeatureCountLimits(t *testing.T) {
+
+	//The last valid row has 6 features, fewer than the 9 features required without limits
+	csv := testCSV(testCSVHeader, 3) + "5\n" + strings.Repeat("1;", 50) + "5\n" + strings.Repeat("2;", 6) + "3\n"
+	options := DefaultCSVOptions()
+	options.MinFeatures = 5
+	options.MaxFeatures = 20
+
+	dataSet, stats, err := ReadCSVDataSetFrom(strings.NewReader(csv), options)
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	if len(dataSet) != 4 || stats.DroppedFeatureCount != 2 {
+		t.Fatalf("expected 4 examples and 2 dropped rows, found %d and %d", len(dataSet), stats.DroppedFeatureCount)
+	}
+	if len(dataSet[3].Features) != 6 || dataSet[3].Label != 3 {
+		t.Fatalf("expected 6 features and label 3, found %v", dataSet[3])
+	}
+}
+
+//writeTestFile writes a file in the test temporary directory and returns its name
+func writeTestFile(t *testing.T, name, content string) string {
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing %s: %v", fileName, err)
+	}
+	return fileName
+}
+
+func TestReadCSVDataSets(t *testing.T) {
+
+	first := writeTestFile(t, "first.csv", testCSV(testCSVHeader, 3))
+	second := writeTestFile(t, "second.csv", testCSV(testCSVHeader, 4))
+
+	dataSet, stats, err := ReadCSVDataSets([]string{first, second}, DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("error reading datasets: %v", err)
+	}
+	if len(dataSet) != 7 || stats.Examples != 7 {
+		t.Fatalf("expected 7 examples, found %d", len(dataSet))
+	}
+	if dataSet[0].Features[0] != 0 || dataSet[3].Features[0] != 0 {
+		t.Fatalf("expected the headers of both files to be skipped, found %v and %v", dataSet[0], dataSet[3])
+	}
+
+	broken := writeTestFile(t, "broken.csv", testCSVHeader+"x;1;2;3;4;5;6;7;8;9;1\n")
+	_, _, err = ReadCSVDataSets([]string{first, broken}, DefaultCSVOptions())
+	if err == nil || !strings.Contains(err.Error(), broken) {
+		t.Fatalf("expected an error naming %s, found %v", broken, err)
+	}
+}
+
+func TestReadCSVDataSetFromReaderMatchesFile(t *testing.T) {
+
+	fromFile, err := ReadCSVDataSet("testdata/wine.csv")
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	content, err := os.ReadFile("testdata/wine.csv")
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	fromReader, _, err := ReadCSVDataSetFrom(strings.NewReader(string(content)), DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("error reading dataset from a reader: %v", err)
+	}
+
+	if len(fromReader) != len(fromFile) {
+		t.Fatalf("expected %d examples, found %d", len(fromFile), len(fromReader))
+	}
+	for i := range fromFile {
+		if fromReader[i].Label != fromFile[i].Label || !equalFloats(fromReader[i].Features, fromFile[i].Features) {
+			t.Fatalf("example %d: expected %v, found %v", i, fromFile[i], fromReader[i])
+		}
+	}
+}
+
+func TestLoadModelEmptyOrCorrupt(t *testing.T) {
+
+	valid, err := os.ReadFile("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error reading model: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		corrupt bool
+	}{
+		{"empty", "", true},
+		{"truncated", string(valid[:len(valid)/2]), true},
+		{"no coefficients", "{\"Bias\": 1}", true},
+		{"valid", string(valid), false},
+	}
+	for _, test := range tests {
+		_, err := LoadModel(writeTestFile(t, "model.json", test.content))
+		if test.corrupt && !errors.Is(err, ErrModelCorrupt) {
+			t.Fatalf("%s: expected ErrModelCorrupt, found %v", test.name, err)
+		}
+		if !test.corrupt && err != nil {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+	}
+}
+
+func TestReadCSVDataSetLabelColumnName(t *testing.T) {
+
+	csv := "quality;f0;f1;f2;f3;f4;f5;f6;f7;f8;f9\n" +
+		"7;0;1;2;3;4;5;6;7;8;9\n" +
+		"3;1;2;3;4;5;6;7;8;9;10\n"
+	options := DefaultCSVOptions()
+	options.LabelColumnName = "quality"
+
+	dataSet, _, err := ReadCSVDataSetFrom(strings.NewReader(csv), options)
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	if len(dataSet) != 2 || dataSet[0].Label != 7 || dataSet[1].Label != 3 ||
+		!equalFloats(dataSet[0].Features, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("expected the first column to be the label, found %v", dataSet)
+	}
+
+	options.LabelColumnName = "score"
+	if _, _, err := ReadCSVDataSetFrom(strings.NewReader(csv), options); err == nil ||
+		!strings.Contains(err.Error(), "score") {
+		t.Fatalf("expected an error naming the missing column, found %v", err)
+	}
+}
+
+func TestReadCSVDataSetSkipsInvalidLabels(t *testing.T) {
+
+	features := "0;1;2;3;4;5;6;7;8;9;"
+	csv := testCSVHeader + features + "5\n" + features + "NaN\n" + features + "Inf\n" +
+		features + "-Inf\n" + features + "1e400\n" + features + "1e10\n"
+
+	dataSet, stats, err := ReadCSVDataSetFrom(strings.NewReader(csv), DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	if len(dataSet) != 2 || stats.DroppedInvalidLabel != 4 {
+		t.Fatalf("expected 2 examples and 4 dropped rows, found %d and %d", len(dataSet), stats.DroppedInvalidLabel)
+	}
+	if dataSet[0].Label != 5 || dataSet[1].Label != 1e10 {
+		t.Fatalf("expected labels 5 and 1e10, found %v and %v", dataSet[0].Label, dataSet[1].Label)
+	}
+}
+
+func TestTrainMaxDuration(t *testing.T) {
+
+	epochs := 0
+	_, ran, err := TrainWithOptions(GenerateSyntheticDataset(100, 3, 0, 1), TrainOptions{LearningRate: 0.01,
+		NumEpochs: 1000, MaxDuration: time.Nanosecond, EpochListener: func(EpochStats) {
+			epochs++
+		}})
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if ran != 1 || epochs != 1 {
+		t.Fatalf("expected training to stop after the first epoch, found %d epochs run and %d reported", ran, epochs)
+	}
+}
+
+func TestGradientNormDecreasesAndStopsTraining(t *testing.T) {
+
+	norms := make([]float64, 0)
+	listener := func(stats EpochStats) {
+		norms = append(norms, stats.GradientNorm)
+	}
+	_, _, err := TrainWithOptions(GenerateSyntheticDataset(500, 3, 0, 1), TrainOptions{LearningRate: 0.01,
+		NumEpochs: 50, EpochListener: listener})
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if norms[len(norms)-1] >= norms[0] {
+		t.Fatalf("expected the gradient norm to decrease, found %v then %v", norms[0], norms[len(norms)-1])
+	}
+
+	tolerance := norms[10]
+	norms = norms[:0]
+	_, ran, err := TrainWithOptions(GenerateSyntheticDataset(500, 3, 0, 1), TrainOptions{LearningRate: 0.01,
+		NumEpochs: 50, GradientTolerance: tolerance, EpochListener: listener})
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if ran >= 50 || norms[len(norms)-1] >= tolerance {
+		t.Fatalf("expected training to stop once the gradient norm is below %v, found %d epochs and norm %v",
+			tolerance, ran, norms[len(norms)-1])
+	}
+}
+
+func TestUnlabeledDataSet(t *testing.T) {
+
+	model, err := LoadModel("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+	csv := "f0;f1;f2;f3;f4;f5;f6;f7;f8;f9;f10\n" +
+		"7;0.27;0.36;20.7;0.045;45;170;1.001;3;0.45;8.8\n" +
+		"6.3;0.3;0.34;1.6;0.049;14;132;0.994;3.3;0.49;9.5\n"
+	options := DefaultCSVOptions()
+	options.Unlabeled = true
+
+	dataSet, _, err := ReadCSVDataSetFrom(strings.NewReader(csv), options)
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	if len(dataSet) != 2 || len(dataSet[0].Features) != 11 || !dataSet[0].Unlabeled || !math.IsNaN(dataSet[0].Label) {
+		t.Fatalf("expected 2 unlabeled examples with 11 features, found %v", dataSet)
+	}
+
+	for _, prediction := range PredictBatch(model, normalizeDataSet(model, dataSet)) {
+		if math.IsNaN(prediction) || prediction < 3 || prediction > 9 {
+			t.Fatalf("expected a wine quality prediction, found %v", prediction)
+		}
+	}
+
+	if _, err := Train(normalizeDataSet(model, dataSet), 0.01, 1); !errors.Is(err, ErrUnlabeled) {
+		t.Fatalf("expected training to fail with ErrUnlabeled, found %v", err)
+	}
+	if loss := Test(model, normalizeDataSet(model, dataSet), func(Example, float64) {}); !math.IsNaN(loss) {
+		t.Fatalf("expected a NaN test loss, found %v", loss)
+	}
+}
+
+func TestLoadLegacyModelWithoutLimits(t *testing.T) {
+
+	model, err := LoadModelFrom(strings.NewReader(`{"Bias": 0.5, "Coeficients": [1, 2]}`))
+	if err != nil {
+		t.Fatalf("error loading legacy model: %v", err)
+	}
+	if !equalFloats(model.MinFeatureValues, []float64{0, 0}) || !equalFloats(model.MaxFeatureValues, []float64{1, 1}) {
+		t.Fatalf("expected limits [0 0] and [1 1], found %v and %v", model.MinFeatureValues, model.MaxFeatureValues)
+	}
+	//Raw features are not changed by the assumed limits
+	if prediction := Predict(model, normalizeExample(model, example(0, 3, 4))); prediction != 11.5 {
+		t.Fatalf("expected prediction 11.5, found %v", prediction)
+	}
+}
+
+func TestLogEvery(t *testing.T) {
+
+	epochs := []int{}
+	_, _, err := TrainWithOptions(GenerateSyntheticDataset(20, 3, 0, 1), TrainOptions{LearningRate: 0.01, NumEpochs: 25,
+		LogEvery: 10, EpochListener: func(stats EpochStats) { epochs = append(epochs, stats.Epoch) }})
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if !equalInts(epochs, []int{0, 10, 20, 24}) {
+		t.Fatalf("expected the listener to be called on epochs [0 10 20 24], found %v", epochs)
+	}
+}
+
+func TestDetectDatasetType(t *testing.T) {
+
+	tests := []struct {
+		fileName string
+		expected DatasetType
+	}{
+		{"testdata/wine.csv", NumericDataset},
+		{"testdata/wine.csv.bz2", NumericDataset},
+		{writeTestFile(t, "sentences.csv", "sentence;label\nthis wine is great;1\nnot my favourite;0\n"), TextDataset},
+	}
+	for _, test := range tests {
+		datasetType, err := DetectDatasetType(test.fileName)
+		if err != nil {
+			t.Fatalf("%s: error detecting the dataset type: %v", test.fileName, err)
+		}
+		if datasetType != test.expected {
+			t.Fatalf("%s: expected dataset type %d, found %d", test.fileName, test.expected, datasetType)
+		}
+	}
+}
+
+func TestDivergingLearningRate(t *testing.T) {
+
+	warnings := 0
+	var last EpochStats
+	options := TrainOptions{LearningRate: 1.1, NumEpochs: 100, EpochListener: func(stats EpochStats) {
+		last = stats
+		if stats.Warning != "" {
+			warnings++
+		}
+	}}
+	if _, _, err := TrainWithOptions(GenerateSyntheticDataset(10, 3, 0, 1), options); err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if warnings == 0 || last.LossValue < 1e6 {
+		t.Fatalf("expected training to diverge with a learning rate warning, found %d warnings and %+v", warnings, last)
+	}
+
+	warnings = 0
+	options.AutoLearningRate = true
+	if _, _, err := TrainWithOptions(GenerateSyntheticDataset(10, 3, 0, 1), options); err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if warnings == 0 || last.LearningRate >= 1.1 || last.LossValue > 0.001 {
+		t.Fatalf("expected the learning rate to be reduced until training converges, found %d warnings and %+v", warnings, last)
+	}
+
+	//Losses overflowing to infinity or NaN are increases too
+	warnings = 0
+	options = TrainOptions{LearningRate: 3, NumEpochs: 20, EpochListener: options.EpochListener}
+	if _, _, err := TrainWithOptions(GenerateSyntheticDataset(100, 3, 0, 1), options); err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+	if !math.IsNaN(last.LossValue) || last.Warning == "" {
+		t.Fatalf("expected a learning rate warning with a NaN loss, found %+v", last)
+	}
+}
+
+func TestReadCSVDataSetsHeaderMismatch(t *testing.T) {
+
+	first := writeTestFile(t, "first.csv", testCSV(testCSVHeader, 3))
+	swapped := strings.Replace(testCSVHeader, "f0;f1", "f1;f0", 1)
+	second := writeTestFile(t, "second.csv", testCSV(swapped, 3))
+
+	_, _, err := ReadCSVDataSets([]string{first, second}, DefaultCSVOptions())
+	if !errors.Is(err, ErrHeaderMismatch) || !strings.Contains(err.Error(), second) {
+		t.Fatalf("expected ErrHeaderMismatch naming %s, found %v", second, err)
+	}
+
+	//Spacing differences are not a mismatch
+	third := writeTestFile(t, "third.csv", testCSV(strings.Replace(testCSVHeader, "f0;", " f0 ;", 1), 3))
+	if _, _, err := ReadCSVDataSets([]string{first, third}, DefaultCSVOptions()); err != nil {
+		t.Fatalf("error reading datasets: %v", err)
+	}
+}
+
+func TestLabelMap(t *testing.T) {
+
+	//Labels 0, 1 and 2 become 1 for class 2 and 0 otherwise
+	options := DefaultCSVOptions()
+	options.LabelMap = func(label float64) (float64, bool) {
+		if label == 2 {
+			return 1, true
+		}
+		return 0, true
+	}
+	dataSet, stats, err := ReadCSVDataSetFrom(strings.NewReader(testCSV(testCSVHeader, 9)), options)
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	counts := Summarize(dataSet).LabelCounts
+	if len(counts) != 2 || counts[0] != 6 || counts[1] != 3 || stats.DroppedByLabelMap != 0 {
+		t.Fatalf("expected 6 examples labeled 0 and 3 labeled 1, found %v", counts)
+	}
+
+	//Examples of class 1 are dropped
+	options.LabelMap = func(label float64) (float64, bool) {
+		return label, label != 1
+	}
+	dataSet, stats, err = ReadCSVDataSetFrom(strings.NewReader(testCSV(testCSVHeader, 9)), options)
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	if len(dataSet) != 6 || stats.DroppedByLabelMap != 3 || Summarize(dataSet).LabelCounts[1] != 0 {
+		t.Fatalf("expected 6 examples and 3 dropped, found %d and %d", len(dataSet), stats.DroppedByLabelMap)
+	}
+}
+
+func TestPartialFitShardsMatchTrainingPass(t *testing.T) {
+
+	//The first shard holds the limits of every feature, so all shards are normalized like the full dataset
+	data := append([]Example{example(0, 0, 0, 0), example(1, 10, 10, 10)}, GenerateSyntheticDataset(98, 3, 0.5, 1)...)
+
+	expected := trainCopy(t, data, TrainOptions{LearningRate: 0.01, NumEpochs: 1})
+
+	var model Model
+	shards := copyExamples(data, indexRange(0, len(data)))
+	for start := 0; start < len(shards); start += 25 {
+		if err := PartialFit(&model, shards[start:start+25], 0.01); err != nil {
+			t.Fatalf("error fitting shard at %d: %v", start, err)
+		}
+	}
+
+	if model.Bias != expected.Bias || !equalFloats(model.Coeficients, expected.Coeficients) ||
+		!equalFloats(model.MinFeatureValues, expected.MinFeatureValues) ||
+		!equalFloats(model.MaxFeatureValues, expected.MaxFeatureValues) {
+		t.Fatalf("expected %v, found %v", expected, model)
+	}
+
+	if err := PartialFit(&model, []Example{example(1, 1, 2)}, 0.01); !errors.Is(err, ErrFeatureCountMismatch) {
+		t.Fatalf("expected ErrFeatureCountMismatch, found %v", err)
+	}
+	if err := PartialFit(&model, []Example{{Features: []float64{1, 2, 3}, Unlabeled: true}}, 0.01); !errors.Is(err, ErrUnlabeled) {
+		t.Fatalf("expected ErrUnlabeled, found %v", err)
+	}
+}
+
+func TestReadGlobDataSetGzipShards(t *testing.T) {
+
+	dir := t.TempDir()
+	for i, rows := range []int{3, 4} {
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		writer.Write([]byte(testCSV(testCSVHeader, rows)))
+		writer.Close()
+		fileName := filepath.Join(dir, fmt.Sprintf("train-%d.csv.gz", i))
+		if err := os.WriteFile(fileName, buffer.Bytes(), 0644); err != nil {
+			t.Fatalf("error writing %s: %v", fileName, err)
+		}
+	}
+	//Not matching the pattern
+	if err := os.WriteFile(filepath.Join(dir, "test-0.csv.gz"), []byte("not gzipped"), 0644); err != nil {
+		t.Fatalf("error writing test-0.csv.gz: %v", err)
+	}
+
+	dataSet, stats, err := ReadGlobDataSet(filepath.Join(dir, "train-*.csv.gz"), DefaultCSVOptions())
+	if err != nil {
+		t.Fatalf("error reading shards: %v", err)
+	}
+	if len(dataSet) != 7 || stats.Examples != 7 {
+		t.Fatalf("expected 7 examples, found %d (stats %d)", len(dataSet), stats.Examples)
+	}
+	//Shards are read in lexical order
+	if dataSet[3].Features[0] != 0 || dataSet[6].Features[0] != 3 {
+		t.Fatalf("expected the second shard after the first, found %v", dataSet)
+	}
+
+	if _, _, err := ReadGlobDataSet(filepath.Join(dir, "validation-*.csv.gz"), DefaultCSVOptions()); err == nil {
+		t.Fatalf("expected an error when no file matches")
+	}
+}
+
+func TestTrainSWA(t *testing.T) {
+
+	data := GenerateSyntheticDataset(200, 5, 2, 1)
+	validation := GenerateSyntheticDataset(1000, 5, 2, 1)[200:]
+	options := TrainOptions{LearningRate: 0.05, NumEpochs: 20}
+
+	final, swa, epochs, err := TrainSWA(copyExamples(data, indexRange(0, len(data))), options, 15)
+	if err != nil || epochs != 20 {
+		t.Fatalf("error training: %v", err)
+	}
+
+	//The SWA model is the average of the models at the end of epochs 15 to 19,
+	//which are the models trained for 16 to 20 epochs
+	expected := Model{Coeficients: make([]float64, 5)}
+	for numEpochs := 16; numEpochs <= 20; numEpochs++ {
+		model := trainCopy(t, data, TrainOptions{LearningRate: 0.05, NumEpochs: numEpochs})
+		expected.Bias += model.Bias / 5
+		for j := range model.Coeficients {
+			expected.Coeficients[j] += model.Coeficients[j] / 5
+		}
+	}
+	if math.Abs(swa.Bias-expected.Bias) > 1e-12 {
+		t.Fatalf("expected SWA bias %v, found %v", expected.Bias, swa.Bias)
+	}
+	for j := range expected.Coeficients {
+		if math.Abs(swa.Coeficients[j]-expected.Coeficients[j]) > 1e-12 {
+			t.Fatalf("expected SWA coefficients %v, found %v", expected.Coeficients, swa.Coeficients)
+		}
+	}
+	if !equalFloats(swa.MinFeatureValues, final.MinFeatureValues) || !equalFloats(swa.MaxFeatureValues, final.MaxFeatureValues) {
+		t.Fatalf("expected the SWA model to have the feature limits of the final model")
+	}
+	//Averaging the last epochs does not hurt generalization
+	if RMSE(swa, validation) > RMSE(final, validation)*1.01 {
+		t.Fatalf("expected the SWA model to generalize as well as the final model, found RMSE %v and %v",
+			RMSE(swa, validation), RMSE(final, validation))
+	}
+
+	//Training stops before the average starts
+	final, swa, _, err = TrainSWA(copyExamples(data, indexRange(0, len(data))), options, 20)
+	if err != nil || swa.Bias != final.Bias || !equalFloats(swa.Coeficients, final.Coeficients) {
+		t.Fatalf("expected the SWA model to be the final model, found %v and %v (%v)", swa, final, err)
+	}
+}
@@ -0,0 +1,265 @@
+package ml
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+)
+
+//Clone returns a deep copy of the model, so changes to the copy do not affect the original
+func (m Model) Clone() Model {
+	return Model{Bias: m.Bias,
+		Coeficients:      cloneFloats(m.Coeficients),
+		MinFeatureValues: cloneFloats(m.MinFeatureValues),
+		MaxFeatureValues: cloneFloats(m.MaxFeatureValues),
+		Threshold:        m.Threshold,
+		Fingerprint:      m.Fingerprint}
+}
+
+func cloneFloats(values []float64) []float64 {
+	if values == nil {
+		return nil
+	}
+	return append(make([]float64, 0, len(values)), values...)
+}
+
+//AverageModels builds a model whose bias and coefficients are the weighted average of the provided models.
+//All models must have the same number of coefficients and the same normalization limits,
+//otherwise their coefficients are not comparable.
+func AverageModels(models []Model, weights []float64) (Model, error) {
+
+	if len(models) == 0 {
+		return Model{}, fmt.Errorf("no models to average")
+	}
+	if len(weights) != len(models) {
+		return Model{}, fmt.Errorf("expected %d weights, found %d", len(models), len(weights))
+	}
+
+	totalWeight := 0.0
+	for i, model := range models {
+		if err := checkCompatible(models[0], model); err != nil {
+			return Model{}, fmt.Errorf("model %d: %w", i, err)
+		}
+		totalWeight += weights[i]
+	}
+	if totalWeight == 0 {
+		return Model{}, fmt.Errorf("weights add up to zero")
+	}
+
+	result := Model{Coeficients: make([]float64, len(models[0].Coeficients)),
+		MinFeatureValues: cloneFloats(models[0].MinFeatureValues),
+		MaxFeatureValues: cloneFloats(models[0].MaxFeatureValues)}
+
+	for i, model := range models {
+		weight := weights[i] / totalWeight
+		result.Bias += weight * model.Bias
+		for j, c := range model.Coeficients {
+			result.Coeficients[j] += weight * c
+		}
+	}
+
+	return result, nil
+}
+
+//checkCompatible verifies that two models share the same features and normalization limits
+func checkCompatible(a, b Model) error {
+
+	if len(a.Coeficients) != len(b.Coeficients) {
+		return fmt.Errorf("%w: expected %d coefficients, found %d", ErrFeatureCountMismatch, len(a.Coeficients), len(b.Coeficients))
+	}
+	if !equalFloats(a.MinFeatureValues, b.MinFeatureValues) || !equalFloats(a.MaxFeatureValues, b.MaxFeatureValues) {
+		return fmt.Errorf("models have different feature normalization limits")
+	}
+	return nil
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//CoeficientChange is the change of a single coefficient between two models
+type CoeficientChange struct {
+	Index int
+	Delta float64
+}
+
+//ModelDiff describes the differences between two models
+type ModelDiff struct {
+	//ChangedCoeficients is the number of coefficients with a different value
+	ChangedCoeficients int
+	//L2Distance is the euclidean distance between the coefficient vectors
+	L2Distance float64
+	BiasDelta  float64
+	//Changes lists the changed coefficients, largest absolute change first
+	Changes []CoeficientChange
+}
+
+//DiffModels compares two models with the same number of coefficients.
+//Deltas are computed as b - a.
+func DiffModels(a, b Model) (ModelDiff, error) {
+
+	if len(a.Coeficients) != len(b.Coeficients) {
+		return ModelDiff{}, fmt.Errorf("%w: expected %d coefficients, found %d", ErrFeatureCountMismatch, len(a.Coeficients), len(b.Coeficients))
+	}
+
+	diff := ModelDiff{BiasDelta: b.Bias - a.Bias, Changes: make([]CoeficientChange, 0)}
+	sumSquares := 0.0
+	for i := range a.Coeficients {
+		delta := b.Coeficients[i] - a.Coeficients[i]
+		if delta == 0 {
+			continue
+		}
+		diff.ChangedCoeficients++
+		sumSquares += delta * delta
+		diff.Changes = append(diff.Changes, CoeficientChange{Index: i, Delta: delta})
+	}
+	diff.L2Distance = math.Sqrt(sumSquares)
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		return math.Abs(diff.Changes[i].Delta) > math.Abs(diff.Changes[j].Delta)
+	})
+
+	return diff, nil
+}
+
+//RangeNonZero calls fn for each non-zero coefficient, in index order, without copying the coefficients.
+//Iteration stops when fn returns false.
+func (m Model) RangeNonZero(fn func(index int, coef float64) bool) {
+	for i, c := range m.Coeficients {
+		if c != 0 && !fn(i, c) {
+			return
+		}
+	}
+}
+
+//ComputeFingerprint returns the hex encoded SHA-256 of the model parameters: the bias, the number of features,
+//the non-zero coefficients with their index, the feature limits and the threshold if set.
+//Models with the same parameters always have the same fingerprint.
+func (m Model) ComputeFingerprint() string {
+
+	hash := sha256.New()
+	buffer := make([]byte, 8)
+	writeUint64 := func(value uint64) {
+		binary.LittleEndian.PutUint64(buffer, value)
+		hash.Write(buffer)
+	}
+
+	writeUint64(math.Float64bits(m.Bias))
+	writeUint64(uint64(len(m.Coeficients)))
+	m.RangeNonZero(func(index int, coef float64) bool {
+		writeUint64(uint64(index))
+		writeUint64(math.Float64bits(coef))
+		return true
+	})
+	for _, limits := range [][]float64{m.MinFeatureValues, m.MaxFeatureValues} {
+		writeUint64(uint64(len(limits)))
+		for _, limit := range limits {
+			writeUint64(math.Float64bits(limit))
+		}
+	}
+	//Models without a threshold keep the fingerprint they had before thresholds were introduced
+	if m.Threshold != 0 {
+		writeUint64(math.Float64bits(m.Threshold))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+//ActiveFeatures returns the indices of the features with a non-zero coefficient, in increasing order
+func (m Model) ActiveFeatures() []int {
+	active := make([]int, 0)
+	m.RangeNonZero(func(index int, coef float64) bool {
+		active = append(active, index)
+		return true
+	})
+	return active
+}
+
+//ActiveCount returns the number of features with a non-zero coefficient
+func (m Model) ActiveCount() int {
+	count := 0
+	m.RangeNonZero(func(index int, coef float64) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+//Summary returns a one-line description of the model
+func (m Model) Summary() string {
+	return fmt.Sprintf("linear model: %d features, %d non-zero coefficients, bias %.3f",
+		len(m.Coeficients), m.ActiveCount(), m.Bias)
+}
+
+//FeatureContribution is the contribution of a feature to a prediction
+type FeatureContribution struct {
+	Feature int
+	//Contribution is the coefficient multiplied by the normalized feature value
+	Contribution float64
+}
+
+//Explain returns the contribution of each feature to the model prediction for an example,
+//largest absolute contribution first. The prediction is the bias plus the sum of all contributions.
+//The example features must not be normalized, and the example is not modified.
+func Explain(model Model, example Example) []FeatureContribution {
+
+	normalized := normalizeExample(model, example)
+	contributions := make([]FeatureContribution, len(normalized.Features))
+	for i, value := range normalized.Features {
+		contributions[i] = FeatureContribution{Feature: i, Contribution: model.Coeficients[i] * value}
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return math.Abs(contributions[i].Contribution) > math.Abs(contributions[j].Contribution)
+	})
+
+	return contributions
+}
+
+//CoefStats summarizes the model coefficients
+type CoefStats struct {
+	Min     float64
+	Max     float64
+	Mean    float64
+	Std     float64
+	L1Norm  float64
+	L2Norm  float64
+	NonZero int
+}
+
+//CoefStats returns statistics of the model coefficients (the bias is not included),
+//useful to spot exploding or dead weights
+func (m Model) CoefStats() CoefStats {
+
+	stats := CoefStats{NonZero: m.ActiveCount()}
+	if len(m.Coeficients) == 0 {
+		return stats
+	}
+
+	stats.Min, stats.Max = math.MaxFloat64, -math.MaxFloat64
+	sum, sumSquares := 0.0, 0.0
+	for _, c := range m.Coeficients {
+		stats.Min = math.Min(stats.Min, c)
+		stats.Max = math.Max(stats.Max, c)
+		stats.L1Norm += math.Abs(c)
+		sum += c
+		sumSquares += c * c
+	}
+	count := float64(len(m.Coeficients))
+	stats.Mean = sum / count
+	stats.Std = math.Sqrt(math.Max(sumSquares/count-stats.Mean*stats.Mean, 0))
+	stats.L2Norm = math.Sqrt(sumSquares)
+
+	return stats
+}
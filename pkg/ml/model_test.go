@@ -0,0 +1,196 @@
+package ml
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+//testModel returns a small model with normalization limits
+func testModel() Model {
+	return Model{Bias: 0.5, Coeficients: []float64{1, 0, -2},
+		MinFeatureValues: []float64{0, 0, 0}, MaxFeatureValues: []float64{1, 2, 4}}
+}
+
+func TestFingerprintStableAcrossSaveLoad(t *testing.T) {
+
+	model := testModel()
+	var buffer bytes.Buffer
+	if err := SaveModelTo(&buffer, model); err != nil {
+		t.Fatalf("error saving model: %v", err)
+	}
+	loaded, err := LoadModelFrom(&buffer)
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+
+	if loaded.Fingerprint == "" || loaded.Fingerprint != model.ComputeFingerprint() {
+		t.Fatalf("expected fingerprint %s, found %s", model.ComputeFingerprint(), loaded.Fingerprint)
+	}
+	if loaded.ComputeFingerprint() != loaded.Fingerprint {
+		t.Fatalf("fingerprint changed after loading")
+	}
+	if loaded.Clone().Fingerprint != loaded.Fingerprint {
+		t.Fatalf("fingerprint not copied by Clone")
+	}
+
+	loaded.Coeficients[1] = 1e-12
+	if loaded.ComputeFingerprint() == loaded.Fingerprint {
+		t.Fatalf("fingerprint did not change with a coefficient")
+	}
+}
+
+func TestAverageModels(t *testing.T) {
+
+	a := testModel()
+	b := testModel()
+	b.Bias = 2.5
+	b.Coeficients = []float64{3, 4, 2}
+
+	average, err := AverageModels([]Model{a, b}, []float64{1, 3})
+	if err != nil {
+		t.Fatalf("error averaging models: %v", err)
+	}
+	if average.Bias != 0.25*a.Bias+0.75*b.Bias {
+		t.Fatalf("expected bias %v, found %v", 0.25*a.Bias+0.75*b.Bias, average.Bias)
+	}
+	for i := range average.Coeficients {
+		expected := 0.25*a.Coeficients[i] + 0.75*b.Coeficients[i]
+		if average.Coeficients[i] != expected {
+			t.Fatalf("coefficient %d: expected %v, found %v", i, expected, average.Coeficients[i])
+		}
+	}
+
+	b.Coeficients = b.Coeficients[:2]
+	if _, err := AverageModels([]Model{a, b}, []float64{1, 1}); !errors.Is(err, ErrFeatureCountMismatch) {
+		t.Fatalf("expected ErrFeatureCountMismatch, found %v", err)
+	}
+}
+
+func TestDiffModels(t *testing.T) {
+
+	a := testModel()
+	b := a.Clone()
+	b.Bias += 0.25
+	b.Coeficients[0] += 3
+	b.Coeficients[2] -= 4
+
+	diff, err := DiffModels(a, b)
+	if err != nil {
+		t.Fatalf("error comparing models: %v", err)
+	}
+	if diff.ChangedCoeficients != 2 || diff.BiasDelta != 0.25 || diff.L2Distance != 5 {
+		t.Fatalf("expected 2 changes, bias delta 0.25 and distance 5, found %+v", diff)
+	}
+	if diff.Changes[0].Index != 2 || diff.Changes[0].Delta != -4 || diff.Changes[1].Index != 0 {
+		t.Fatalf("expected the largest change first, found %+v", diff.Changes)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+
+	model := testModel()
+	model.Fingerprint = "fingerprint"
+	clone := model.Clone()
+
+	clone.Bias = 10
+	clone.Coeficients[0] = 10
+	clone.MinFeatureValues[0] = 10
+	clone.MaxFeatureValues[0] = 10
+
+	original := testModel()
+	if model.Bias != original.Bias || !equalFloats(model.Coeficients, original.Coeficients) ||
+		!equalFloats(model.MinFeatureValues, original.MinFeatureValues) ||
+		!equalFloats(model.MaxFeatureValues, original.MaxFeatureValues) {
+		t.Fatalf("mutating the clone changed the original model: %v", model)
+	}
+	if clone.Fingerprint != model.Fingerprint {
+		t.Fatalf("expected fingerprint %s, found %s", model.Fingerprint, clone.Fingerprint)
+	}
+}
+
+func TestActiveFeatures(t *testing.T) {
+
+	model := Model{Coeficients: []float64{0, 1.5, 0, 0, -2, 0, 1e-9}}
+	if active := model.ActiveFeatures(); !equalInts(active, []int{1, 4, 6}) {
+		t.Fatalf("expected active features [1 4 6], found %v", active)
+	}
+	if count := model.ActiveCount(); count != 3 {
+		t.Fatalf("expected 3 active features, found %d", count)
+	}
+}
+
+func TestSummary(t *testing.T) {
+
+	summary := testModel().Summary()
+	for _, expected := range []string{"3 features", "2 non-zero coefficients", "bias 0.500"} {
+		if !strings.Contains(summary, expected) {
+			t.Fatalf("expected the summary to contain %q, found %q", expected, summary)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+
+	//Normalized features: 1, 0.5 and 0.25
+	contributions := Explain(testModel(), example(0, 1, 1, 1))
+
+	expected := []FeatureContribution{{Feature: 0, Contribution: 1}, {Feature: 2, Contribution: -0.5}, {Feature: 1}}
+	if len(contributions) != 3 {
+		t.Fatalf("expected 3 contributions, found %v", contributions)
+	}
+	for i := range expected {
+		if contributions[i] != expected[i] {
+			t.Fatalf("expected contributions %v, found %v", expected, contributions)
+		}
+	}
+
+	sum := testModel().Bias
+	for _, contribution := range contributions {
+		sum += contribution.Contribution
+	}
+	if prediction := Predict(testModel(), normalizeExample(testModel(), example(0, 1, 1, 1))); sum != prediction {
+		t.Fatalf("expected the contributions to add up to the prediction %v, found %v", prediction, sum)
+	}
+}
+
+func TestCoefStats(t *testing.T) {
+
+	stats := Model{Bias: 100, Coeficients: []float64{3, -4, 0, 1}}.CoefStats()
+
+	expected := CoefStats{Min: -4, Max: 3, Mean: 0, Std: math.Sqrt(6.5), L1Norm: 8, L2Norm: math.Sqrt(26), NonZero: 3}
+	if stats != expected {
+		t.Fatalf("expected %+v, found %+v", expected, stats)
+	}
+	if stats := (Model{}).CoefStats(); stats != (CoefStats{}) {
+		t.Fatalf("expected zero statistics without coefficients, found %+v", stats)
+	}
+}
+
+func TestRangeNonZero(t *testing.T) {
+
+	model := Model{Coeficients: []float64{0, 1.5, 0, -2, 0, 3}}
+
+	visited := []int{}
+	model.RangeNonZero(func(index int, coef float64) bool {
+		if coef != model.Coeficients[index] {
+			t.Fatalf("index %d: expected coefficient %v, found %v", index, model.Coeficients[index], coef)
+		}
+		visited = append(visited, index)
+		return true
+	})
+	if !equalInts(visited, []int{1, 3, 5}) {
+		t.Fatalf("expected to visit [1 3 5], found %v", visited)
+	}
+
+	visited = visited[:0]
+	model.RangeNonZero(func(index int, coef float64) bool {
+		visited = append(visited, index)
+		return len(visited) < 2
+	})
+	if !equalInts(visited, []int{1, 3}) {
+		t.Fatalf("expected to stop after [1 3], found %v", visited)
+	}
+}
@@ -0,0 +1,151 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//OrdinalModel is a cumulative logit model for ordered labels (such as wine quality scores).
+//For classes c0 < c1 < ... < cK-1 it learns shared coefficients and K-1 increasing thresholds:
+// P(label <= ck) = sigmoid(Thresholds[k] - (c0*feature[0]+...+cN*feature[n]))
+//so mistaking a 5 for a 4 costs less than mistaking it for an 8.
+type OrdinalModel struct {
+	//Classes are the label values, in increasing order
+	Classes          []float64
+	Thresholds       []float64
+	Coeficients      []float64
+	MinFeatureValues []float64
+	MaxFeatureValues []float64
+}
+
+//TrainOrdinal trains an ordinal model with stochastic gradient descent on the negative log-likelihood.
+//Like Train, it normalizes the dataset features in place.
+func TrainOrdinal(dataSet []Example, learningRate float64, numEpochs int) (OrdinalModel, error) {
+
+	if err := checkLabeled(dataSet); err != nil {
+		return OrdinalModel{}, err
+	}
+
+	min, max, err := NormalizeDataSetFeatures(dataSet)
+	if err != nil {
+		return OrdinalModel{}, fmt.Errorf("error normalizing dataset: %w", err)
+	}
+
+	classes := Summarize(dataSet).LabelCounts
+	model := OrdinalModel{Classes: make([]float64, 0, len(classes)),
+		Coeficients:      make([]float64, len(dataSet[0].Features)),
+		MinFeatureValues: min, MaxFeatureValues: max}
+	for class := range classes {
+		model.Classes = append(model.Classes, class)
+	}
+	sort.Float64s(model.Classes)
+	if len(model.Classes) < 2 {
+		return OrdinalModel{}, fmt.Errorf("expected at least 2 distinct labels, found %d", len(model.Classes))
+	}
+
+	classIndex := make(map[float64]int, len(model.Classes))
+	for i, class := range model.Classes {
+		classIndex[class] = i
+	}
+
+	//Thresholds start evenly spaced around zero
+	model.Thresholds = make([]float64, len(model.Classes)-1)
+	for k := range model.Thresholds {
+		model.Thresholds[k] = float64(k) - float64(len(model.Thresholds)-1)/2
+	}
+
+	for epoch := 0; epoch < numEpochs; epoch++ {
+		for _, example := range dataSet {
+			model.update(example, classIndex[example.Label], learningRate)
+		}
+	}
+
+	return model, nil
+}
+
+//update applies a gradient descent step for an example of the class at index k
+func (m *OrdinalModel) update(example Example, k int, learningRate float64) {
+
+	score := m.score(example)
+
+	//upper is P(label <= ck), lower is P(label <= ck-1)
+	upper, lower := 1.0, 0.0
+	if k < len(m.Thresholds) {
+		upper = sigmoid(m.Thresholds[k] - score)
+	}
+	if k > 0 {
+		lower = sigmoid(m.Thresholds[k-1] - score)
+	}
+	probability := math.Max(upper-lower, 1e-15)
+	upperSlope := upper * (1 - upper)
+	lowerSlope := lower * (1 - lower)
+
+	//Derivatives of the negative log-likelihood, -log(upper-lower)
+	if k < len(m.Thresholds) {
+		m.Thresholds[k] += learningRate * upperSlope / probability
+	}
+	if k > 0 {
+		m.Thresholds[k-1] -= learningRate * lowerSlope / probability
+	}
+	scoreGradient := (upperSlope - lowerSlope) / probability
+	for j := range m.Coeficients {
+		m.Coeficients[j] -= learningRate * scoreGradient * example.Features[j]
+	}
+
+	//Keep the thresholds ordered
+	for j := 1; j < len(m.Thresholds); j++ {
+		m.Thresholds[j] = math.Max(m.Thresholds[j], m.Thresholds[j-1])
+	}
+}
+
+func (m OrdinalModel) score(example Example) float64 {
+	score := 0.0
+	for j := range m.Coeficients {
+		score += m.Coeficients[j] * example.Features[j]
+	}
+	return score
+}
+
+//ClassProbabilities returns the probability of each class (see Classes) for an example
+//with normalized features
+func (m OrdinalModel) ClassProbabilities(example Example) []float64 {
+
+	score := m.score(example)
+	probabilities := make([]float64, len(m.Classes))
+	lower := 0.0
+	for k := range m.Classes {
+		upper := 1.0
+		if k < len(m.Thresholds) {
+			upper = sigmoid(m.Thresholds[k] - score)
+		}
+		probabilities[k] = upper - lower
+		lower = upper
+	}
+	return probabilities
+}
+
+//PredictOrdinal returns the most likely class for an example with normalized features
+func PredictOrdinal(model OrdinalModel, example Example) float64 {
+
+	probabilities := model.ClassProbabilities(example)
+	best := 0
+	for k, probability := range probabilities {
+		if probability > probabilities[best] {
+			best = k
+		}
+	}
+	return model.Classes[best]
+}
+
+//OrdinalMAE returns the mean absolute error of the model predictions on the dataset.
+//The dataset features must not be normalized, and the dataset is not modified.
+func OrdinalMAE(model OrdinalModel, data []Example) float64 {
+
+	limits := Model{MinFeatureValues: model.MinFeatureValues, MaxFeatureValues: model.MaxFeatureValues}
+	sumError := 0.0
+	for _, example := range data {
+		sumError += math.Abs(PredictOrdinal(model, normalizeExample(limits, example)) - example.Label)
+	}
+	return sumError / float64(len(data))
+}
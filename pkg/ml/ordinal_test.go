@@ -0,0 +1,45 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+//ordinalDataset returns examples with 2 features in [0, 10) and labels from 3 to 6
+//increasing with a noisy linear score of the features
+func ordinalDataset(n int, seed int64) []Example {
+	random := rand.New(rand.NewSource(seed))
+	data := make([]Example, n)
+	for i := range data {
+		x, y := random.Float64()*10, random.Float64()*10
+		score := (2*x-y+10)/30*4 + random.NormFloat64()*0.2
+		data[i] = example(3+math.Min(3, math.Max(0, math.Floor(score))), x, y)
+	}
+	return data
+}
+
+func TestTrainOrdinal(t *testing.T) {
+
+	data := ordinalDataset(1000, 1)
+	model, err := TrainOrdinal(copyExamples(data, indexRange(0, len(data))), 0.05, 50)
+	if err != nil {
+		t.Fatalf("error training: %v", err)
+	}
+
+	if !equalFloats(model.Classes, []float64{3, 4, 5, 6}) || len(model.Thresholds) != 3 {
+		t.Fatalf("expected classes [3 4 5 6] and 3 thresholds, found %v and %v", model.Classes, model.Thresholds)
+	}
+	for k := 1; k < len(model.Thresholds); k++ {
+		if model.Thresholds[k] <= model.Thresholds[k-1] {
+			t.Fatalf("expected increasing thresholds, found %v", model.Thresholds)
+		}
+	}
+	if mae := OrdinalMAE(model, ordinalDataset(500, 2)); mae > 0.25 {
+		t.Fatalf("expected a low MAE, found %v", mae)
+	}
+
+	if _, err := TrainOrdinal([]Example{example(1, 1), example(1, 2)}, 0.05, 1); err == nil {
+		t.Fatalf("expected an error for a single label")
+	}
+}
@@ -0,0 +1,38 @@
+package ml
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+//PredictionRecord is a line written by WritePredictionsJSONL
+type PredictionRecord struct {
+	Features []float64
+	//Label is omitted for unlabeled examples
+	Label *float64 `json:",omitempty"`
+	//Prediction is the raw model prediction
+	Prediction float64
+	//PredictedLabel is the prediction rounded to the nearest integer, as scored by Accuracy
+	PredictedLabel float64
+}
+
+//WritePredictionsJSONL writes one JSON object (see PredictionRecord) per line for each example.
+//The dataset features must not be normalized, and the dataset is not modified.
+func WritePredictionsJSONL(model Model, data []Example, w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	for _, example := range data {
+		prediction := Predict(model, normalizeExample(model, example))
+		record := PredictionRecord{Features: example.Features, Prediction: prediction,
+			PredictedLabel: math.Round(prediction)}
+		if !example.Unlabeled {
+			label := example.Label
+			record.Label = &label
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package ml
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWritePredictionsJSONL(t *testing.T) {
+
+	model := testModel()
+	data := []Example{example(2, 1, 1, 1), {Features: []float64{0, 2, 4}, Unlabeled: true}}
+
+	var buffer bytes.Buffer
+	if err := WritePredictionsJSONL(model, data, &buffer); err != nil {
+		t.Fatalf("error writing predictions: %v", err)
+	}
+
+	records := []PredictionRecord{}
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var record PredictionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("error decoding line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 lines, found %d", len(records))
+	}
+
+	//0.5 + 1*1 + 0*0.5 - 2*0.25
+	if records[0].Label == nil || *records[0].Label != 2 || records[0].Prediction != 1 || records[0].PredictedLabel != 1 ||
+		!equalFloats(records[0].Features, []float64{1, 1, 1}) {
+		t.Fatalf("unexpected first record %+v", records[0])
+	}
+	//0.5 + 0 + 0 - 2*1
+	if records[1].Label != nil || records[1].Prediction != -1.5 || records[1].PredictedLabel != -2 {
+		t.Fatalf("unexpected second record %+v", records[1])
+	}
+}
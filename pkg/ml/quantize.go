@@ -0,0 +1,103 @@
+package ml
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+)
+
+//QuantizedModel is a Model whose coefficients are stored as 8 bit integers.
+//A coefficient is recovered as (Coeficients[i] - ZeroPoint) * Scale
+type QuantizedModel struct {
+	Bias             float64
+	Coeficients      []int8
+	Scale            float64
+	ZeroPoint        int8
+	MinFeatureValues []float64
+	MaxFeatureValues []float64
+}
+
+//Quantize linearly maps the model coefficients to int8 values
+func Quantize(model Model) QuantizedModel {
+
+	minCoeficient, maxCoeficient := 0.0, 0.0
+	for _, c := range model.Coeficients {
+		minCoeficient = math.Min(minCoeficient, c)
+		maxCoeficient = math.Max(maxCoeficient, c)
+	}
+
+	scale := (maxCoeficient - minCoeficient) / 255
+	if scale == 0 {
+		scale = 1
+	}
+	zeroPoint := clampInt8(math.MinInt8 - math.Round(minCoeficient/scale))
+
+	quantized := QuantizedModel{Bias: model.Bias, Coeficients: make([]int8, len(model.Coeficients)),
+		Scale: scale, ZeroPoint: zeroPoint,
+		MinFeatureValues: model.MinFeatureValues, MaxFeatureValues: model.MaxFeatureValues}
+
+	for i, c := range model.Coeficients {
+		quantized.Coeficients[i] = clampInt8(math.Round(c/scale) + float64(zeroPoint))
+	}
+
+	return quantized
+}
+
+//Dequantize converts a quantized model back to a float model
+func Dequantize(model QuantizedModel) Model {
+
+	result := Model{Bias: model.Bias, Coeficients: make([]float64, len(model.Coeficients)),
+		MinFeatureValues: model.MinFeatureValues, MaxFeatureValues: model.MaxFeatureValues}
+
+	for i := range model.Coeficients {
+		result.Coeficients[i] = model.coeficient(i)
+	}
+	return result
+}
+
+//PredictQuantized makes a prediction for a single example, dequantizing the coefficients on the fly
+func PredictQuantized(model QuantizedModel, example Example) float64 {
+
+	result := model.Bias
+
+	for i := 0; i < len(example.Features); i++ {
+		result += model.coeficient(i) * example.Features[i]
+	}
+	return result
+
+}
+
+func (m QuantizedModel) coeficient(i int) float64 {
+	return float64(int(m.Coeficients[i])-int(m.ZeroPoint)) * m.Scale
+}
+
+//SaveQuantizedModel saves a quantized model to a file in JSON format
+func SaveQuantizedModel(model QuantizedModel, fileName string) error {
+
+	content, err := json.MarshalIndent(model, " ", " ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fileName, content, 0644)
+
+}
+
+//LoadQuantizedModel loads a quantized model from a file
+func LoadQuantizedModel(fileName string) (QuantizedModel, error) {
+
+	model := QuantizedModel{}
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return model, err
+	}
+
+	err = json.Unmarshal(content, &model)
+
+	return model, err
+
+}
+
+func clampInt8(value float64) int8 {
+	return int8(math.Max(math.MinInt8, math.Min(math.MaxInt8, value)))
+}
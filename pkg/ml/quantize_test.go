@@ -0,0 +1,40 @@
+package ml
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuantizedPredictionsCloseToModel(t *testing.T) {
+
+	model, err := LoadModel("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+	dataSet, err := ReadCSVDataSet("testdata/wine.csv")
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "quantized.model")
+	if err := SaveQuantizedModel(Quantize(model), fileName); err != nil {
+		t.Fatalf("error saving quantized model: %v", err)
+	}
+	quantized, err := LoadQuantizedModel(fileName)
+	if err != nil {
+		t.Fatalf("error loading quantized model: %v", err)
+	}
+
+	for _, example := range normalizeDataSet(model, dataSet) {
+		//Each coefficient is off by at most half the scale
+		tolerance := 1e-9
+		for _, feature := range example.Features {
+			tolerance += math.Abs(feature) * quantized.Scale / 2
+		}
+		expected, found := Predict(model, example), PredictQuantized(quantized, example)
+		if math.Abs(expected-found) > tolerance {
+			t.Fatalf("expected prediction %v within %v, found %v", expected, tolerance, found)
+		}
+	}
+}
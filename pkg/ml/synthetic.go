@@ -0,0 +1,33 @@
+package ml
+
+import (
+	"math/rand"
+)
+
+//GenerateSyntheticDataset generates n examples with numFeatures features, whose labels are a
+//linear function of the features plus gaussian noise with the provided standard deviation.
+//Features are uniformly distributed in [0, 10), and the coefficients and bias in [-1, 1).
+//The same seed always generates the same dataset, and a noise of 0 makes it perfectly learnable.
+func GenerateSyntheticDataset(n, numFeatures int, noise float64, seed int64) []Example {
+
+	random := rand.New(rand.NewSource(seed))
+
+	bias := random.Float64()*2 - 1
+	coeficients := make([]float64, numFeatures)
+	for j := range coeficients {
+		coeficients[j] = random.Float64()*2 - 1
+	}
+
+	dataSet := make([]Example, n)
+	for i := range dataSet {
+		example := Example{Features: make([]float64, numFeatures), Label: bias}
+		for j := range example.Features {
+			example.Features[j] = random.Float64() * 10
+			example.Label += coeficients[j] * example.Features[j]
+		}
+		example.Label += random.NormFloat64() * noise
+		dataSet[i] = example
+	}
+
+	return dataSet
+}
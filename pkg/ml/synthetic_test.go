@@ -0,0 +1,37 @@
+package ml
+
+import (
+	"testing"
+)
+
+func TestGenerateSyntheticDatasetIsReproducible(t *testing.T) {
+
+	a := GenerateSyntheticDataset(50, 4, 0.5, 1)
+	b := GenerateSyntheticDataset(50, 4, 0.5, 1)
+	c := GenerateSyntheticDataset(50, 4, 0.5, 2)
+
+	if len(a) != 50 || len(a[0].Features) != 4 {
+		t.Fatalf("expected 50 examples with 4 features, found %d with %d", len(a), len(a[0].Features))
+	}
+	for i := range a {
+		if a[i].Label != b[i].Label || !equalFloats(a[i].Features, b[i].Features) {
+			t.Fatalf("example %d: expected the same seed to generate %v, found %v", i, a[i], b[i])
+		}
+	}
+	if a[0].Label == c[0].Label {
+		t.Fatalf("expected different seeds to generate different datasets")
+	}
+}
+
+func TestGenerateSyntheticDatasetWithoutNoiseIsLearnable(t *testing.T) {
+
+	data := GenerateSyntheticDataset(200, 4, 0, 1)
+	model := trainCopy(t, data, TrainOptions{LearningRate: 0.1, NumEpochs: 100})
+
+	if rmse := RMSE(model, data); rmse > 0.001 {
+		t.Fatalf("expected a perfect fit, found RMSE %v", rmse)
+	}
+	if r2 := R2(model, data); r2 < 0.9999 {
+		t.Fatalf("expected R2 close to 1, found %v", r2)
+	}
+}
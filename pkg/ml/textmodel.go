@@ -0,0 +1,173 @@
+package ml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//SaveModelText saves a model to a file in a line oriented text format meant to be diffed:
+//a header with the bias, the threshold (if set) and the number of features, one "limits<TAB>index<TAB>min<TAB>max" line
+//per feature with its normalization limits, followed by one "index<TAB>coefficient" line
+//per non-zero coefficient, in index order.
+//Values are written with full precision, so the model is restored exactly by LoadModelText.
+func SaveModelText(model Model, fileName string) error {
+
+	if len(model.MinFeatureValues) != len(model.Coeficients) || len(model.MaxFeatureValues) != len(model.Coeficients) {
+		return fmt.Errorf("expected %d feature limits, found %d minimum and %d maximum values",
+			len(model.Coeficients), len(model.MinFeatureValues), len(model.MaxFeatureValues))
+	}
+
+	outputFile, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(outputFile)
+	fmt.Fprintf(writer, "bias\t%s\n", formatFloat(model.Bias))
+	if model.Threshold != 0 {
+		fmt.Fprintf(writer, "threshold\t%s\n", formatFloat(model.Threshold))
+	}
+	fmt.Fprintf(writer, "features\t%d\n", len(model.Coeficients))
+	for i := range model.Coeficients {
+		fmt.Fprintf(writer, "limits\t%d\t%s\t%s\n", i,
+			formatFloat(model.MinFeatureValues[i]), formatFloat(model.MaxFeatureValues[i]))
+	}
+	model.RangeNonZero(func(index int, coef float64) bool {
+		fmt.Fprintf(writer, "%d\t%s\n", index, formatFloat(coef))
+		return true
+	})
+
+	err = writer.Flush()
+	if err != nil {
+		outputFile.Close()
+		return err
+	}
+	return outputFile.Close()
+
+}
+
+//LoadModelText loads a model saved by SaveModelText
+func LoadModelText(fileName string) (Model, error) {
+
+	inputFile, err := os.Open(fileName)
+	if err != nil {
+		return Model{}, err
+	}
+	defer inputFile.Close()
+
+	return readModelText(inputFile)
+}
+
+func readModelText(r io.Reader) (Model, error) {
+
+	model := Model{}
+	//limitsRead tells which features had their limits read, and is nil until the features header is read
+	var limitsRead []bool
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.SplitN(scanner.Text(), "\t", 2)
+		if len(fields) != 2 {
+			return Model{}, fmt.Errorf("%w: line %d: expected 2 tab separated fields", ErrModelCorrupt, lineNumber)
+		}
+
+		var err error
+		switch fields[0] {
+		case "bias":
+			model.Bias, err = strconv.ParseFloat(fields[1], 64)
+		case "threshold":
+			model.Threshold, err = strconv.ParseFloat(fields[1], 64)
+		case "features":
+			var numFeatures int
+			numFeatures, err = strconv.Atoi(fields[1])
+			if err == nil && numFeatures < 0 {
+				err = fmt.Errorf("invalid number of features %d", numFeatures)
+			}
+			if err == nil && limitsRead != nil {
+				err = fmt.Errorf("duplicate features header")
+			}
+			if err == nil {
+				model.Coeficients = make([]float64, numFeatures)
+				model.MinFeatureValues = make([]float64, numFeatures)
+				model.MaxFeatureValues = make([]float64, numFeatures)
+				limitsRead = make([]bool, numFeatures)
+			}
+		case "limits":
+			if limitsRead == nil {
+				err = fmt.Errorf("limits found before the features header")
+			} else {
+				err = parseLimits(fields[1], model, limitsRead)
+			}
+		default:
+			var index int
+			index, err = strconv.Atoi(fields[0])
+			if err == nil && limitsRead == nil {
+				err = fmt.Errorf("coefficient %d found before the features header", index)
+			}
+			if err == nil && (index < 0 || index >= len(model.Coeficients)) {
+				err = fmt.Errorf("coefficient %d out of range", index)
+			}
+			if err == nil {
+				model.Coeficients[index], err = strconv.ParseFloat(fields[1], 64)
+			}
+		}
+		if err != nil {
+			return Model{}, newCauseError(ErrModelCorrupt, err, fmt.Sprintf("%v: line %d: %v", ErrModelCorrupt, lineNumber, err))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Model{}, err
+	}
+
+	if limitsRead == nil {
+		return Model{}, fmt.Errorf("%w: no features header found", ErrModelCorrupt)
+	}
+	if len(model.Coeficients) == 0 {
+		return Model{}, fmt.Errorf("%w: no coefficients found", ErrModelCorrupt)
+	}
+	for i, read := range limitsRead {
+		if !read {
+			return Model{}, fmt.Errorf("%w: no limits found for feature %d", ErrModelCorrupt, i)
+		}
+	}
+
+	return model, nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+//parseLimits parses the "index<TAB>min<TAB>max" limits of a feature into the model
+func parseLimits(text string, model Model, limitsRead []bool) error {
+
+	fields := strings.Split(text, "\t")
+	if len(fields) != 3 {
+		return fmt.Errorf("expected index, minimum and maximum limits")
+	}
+	index, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(limitsRead) {
+		return fmt.Errorf("limits of feature %d out of range", index)
+	}
+	if limitsRead[index] {
+		return fmt.Errorf("duplicate limits of feature %d", index)
+	}
+	model.MinFeatureValues[index], err = strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return err
+	}
+	model.MaxFeatureValues[index], err = strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return err
+	}
+	limitsRead[index] = true
+	return nil
+}
@@ -0,0 +1,73 @@
+package ml
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModelTextRoundTrip(t *testing.T) {
+
+	//Large enough for the limits not to fit in a single line
+	const numFeatures = 5000
+	model := Model{Bias: 0.1, Coeficients: make([]float64, numFeatures),
+		MinFeatureValues: make([]float64, numFeatures), MaxFeatureValues: make([]float64, numFeatures)}
+	for i := 0; i < numFeatures; i++ {
+		if i%3 == 0 {
+			model.Coeficients[i] = float64(i) / 7
+		}
+		model.MinFeatureValues[i] = -float64(i) / 3
+		model.MaxFeatureValues[i] = float64(i) + 0.123456789
+	}
+
+	fileName := filepath.Join(t.TempDir(), "model.txt")
+	if err := SaveModelText(model, fileName); err != nil {
+		t.Fatalf("error saving model: %v", err)
+	}
+	loaded, err := LoadModelText(fileName)
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+
+	example := Example{Features: make([]float64, numFeatures)}
+	for i := range example.Features {
+		example.Features[i] = float64(i%10) / 10
+	}
+	if Predict(loaded, example) != Predict(model, example) {
+		t.Fatalf("expected prediction %v, found %v", Predict(model, example), Predict(loaded, example))
+	}
+	if loaded.Bias != model.Bias || !equalFloats(loaded.Coeficients, model.Coeficients) ||
+		!equalFloats(loaded.MinFeatureValues, model.MinFeatureValues) ||
+		!equalFloats(loaded.MaxFeatureValues, model.MaxFeatureValues) {
+		t.Fatalf("loaded model differs from the saved one")
+	}
+}
+
+func TestLoadModelTextCorrupt(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"empty", "", "no features header"},
+		{"bias only", "bias\t3\n", "no features header"},
+		{"no coefficients", "bias\t3\nfeatures\t0\n", "no coefficients"},
+		{"truncated limits", "bias\t3\nfeatures\t2\nlimits\t0\t0\t1\n", "no limits found for feature 1"},
+		{"coefficient before header", "bias\t3\n0\t1.5\nfeatures\t1\nlimits\t0\t0\t1\n", "before the features header"},
+		{"limits before header", "bias\t3\nlimits\t0\t0\t1\nfeatures\t1\n", "before the features header"},
+		{"duplicate header", "features\t1\nfeatures\t1\nlimits\t0\t0\t1\n", "duplicate features header"},
+	}
+	for _, test := range tests {
+		fileName := filepath.Join(t.TempDir(), "model.txt")
+		if err := os.WriteFile(fileName, []byte(test.content), 0644); err != nil {
+			t.Fatalf("error writing model: %v", err)
+		}
+		_, err := LoadModelText(fileName)
+		if !errors.Is(err, ErrModelCorrupt) || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("%s: expected ErrModelCorrupt mentioning %q, found %v", test.name, test.expected, err)
+		}
+	}
+}
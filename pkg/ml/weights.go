@@ -0,0 +1,179 @@
+package ml
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//npyMagic is the magic string starting every NumPy .npy file
+const npyMagic = "\x93NUMPY"
+
+//WeightsMetadata holds the model parameters stored alongside exported weights
+type WeightsMetadata struct {
+	Bias             float64
+	NumFeatures      int
+	MinFeatureValues []float64
+	MaxFeatureValues []float64
+}
+
+//ExportWeightsNPY writes the model coefficients to fileName in NumPy .npy format
+//(a 1-dimensional little-endian float64 array), so they can be read with numpy.load.
+//The bias and the normalization limits are written to a sidecar JSON file
+//with the same name and a .json extension.
+func ExportWeightsNPY(model Model, fileName string) error {
+
+	outputFile, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(outputFile)
+	writer.WriteString(npyHeader(len(model.Coeficients)))
+	for _, c := range model.Coeficients {
+		binary.Write(writer, binary.LittleEndian, c)
+	}
+	err = writer.Flush()
+	if err != nil {
+		outputFile.Close()
+		return err
+	}
+	err = outputFile.Close()
+	if err != nil {
+		return err
+	}
+
+	metadata := WeightsMetadata{Bias: model.Bias, NumFeatures: len(model.Coeficients),
+		MinFeatureValues: model.MinFeatureValues, MaxFeatureValues: model.MaxFeatureValues}
+	content, err := json.MarshalIndent(metadata, " ", " ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(weightsMetadataFileName(fileName), content, 0644)
+
+}
+
+//npyHeader builds a version 1.0 .npy header for a float64 vector of the provided length
+func npyHeader(length int) string {
+
+	dict := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d,), }", length)
+
+	//magic (6) + version (2) + header length (2) + dict + newline must be a multiple of 64
+	prefixLength := len(npyMagic) + 4
+	padding := 64 - (prefixLength+len(dict)+1)%64
+	if padding == 64 {
+		padding = 0
+	}
+	dict += strings.Repeat(" ", padding) + "\n"
+
+	header := make([]byte, prefixLength, prefixLength+len(dict))
+	copy(header, npyMagic)
+	header[6], header[7] = 1, 0
+	binary.LittleEndian.PutUint16(header[8:], uint16(len(dict)))
+
+	return string(append(header, dict...))
+}
+
+//weightsMetadataFileName returns the name of the sidecar file for an exported weights file
+func weightsMetadataFileName(fileName string) string {
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".json"
+}
+
+//ImportWeights builds a model from weights trained elsewhere: fileName holds the coefficients,
+//either in NumPy .npy format (if it has a .npy extension) or as a raw little-endian float64 array.
+//The bias and the normalization limits are read from the sidecar JSON file written by ExportWeightsNPY.
+func ImportWeights(fileName string) (Model, error) {
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return Model{}, err
+	}
+
+	if strings.EqualFold(filepath.Ext(fileName), ".npy") {
+		content, err = npyData(content)
+		if err != nil {
+			return Model{}, fmt.Errorf("error reading %s: %w", fileName, err)
+		}
+	}
+
+	coeficients, err := float64sFromBytes(content)
+	if err != nil {
+		return Model{}, fmt.Errorf("error reading %s: %w", fileName, err)
+	}
+
+	metadataFileName := weightsMetadataFileName(fileName)
+	metadataContent, err := ioutil.ReadFile(metadataFileName)
+	if err != nil {
+		return Model{}, err
+	}
+	metadata := WeightsMetadata{}
+	err = json.Unmarshal(metadataContent, &metadata)
+	if err != nil {
+		return Model{}, fmt.Errorf("error reading %s: %w", metadataFileName, err)
+	}
+
+	if len(coeficients) != metadata.NumFeatures {
+		return Model{}, fmt.Errorf("%w: expected %d coefficients, found %d", ErrFeatureCountMismatch, metadata.NumFeatures, len(coeficients))
+	}
+	if len(metadata.MinFeatureValues) != metadata.NumFeatures || len(metadata.MaxFeatureValues) != metadata.NumFeatures {
+		return Model{}, fmt.Errorf("%w: expected %d feature limits, found %d minimum and %d maximum values",
+			ErrFeatureCountMismatch, metadata.NumFeatures, len(metadata.MinFeatureValues), len(metadata.MaxFeatureValues))
+	}
+
+	return Model{Bias: metadata.Bias, Coeficients: coeficients,
+		MinFeatureValues: metadata.MinFeatureValues, MaxFeatureValues: metadata.MaxFeatureValues}, nil
+
+}
+
+//npyData validates a .npy file containing a float64 vector and returns its data section
+func npyData(content []byte) ([]byte, error) {
+
+	if len(content) < len(npyMagic)+4 || string(content[:len(npyMagic)]) != npyMagic {
+		return nil, fmt.Errorf("not a .npy file")
+	}
+
+	var headerStart, headerLength int
+	switch content[6] {
+	case 1:
+		headerStart = len(npyMagic) + 4
+		headerLength = int(binary.LittleEndian.Uint16(content[8:]))
+	case 2, 3:
+		if len(content) < len(npyMagic)+6 {
+			return nil, fmt.Errorf("truncated .npy header")
+		}
+		headerStart = len(npyMagic) + 6
+		headerLength = int(binary.LittleEndian.Uint32(content[8:]))
+	default:
+		return nil, fmt.Errorf("unsupported .npy version %d", content[6])
+	}
+	if len(content) < headerStart+headerLength {
+		return nil, fmt.Errorf("truncated .npy header")
+	}
+
+	header := string(content[headerStart : headerStart+headerLength])
+	if !strings.Contains(header, "'descr': '<f8'") {
+		return nil, fmt.Errorf("expected a little-endian float64 array, found header %s", strings.TrimSpace(header))
+	}
+
+	return content[headerStart+headerLength:], nil
+}
+
+//float64sFromBytes decodes a little-endian float64 array
+func float64sFromBytes(content []byte) ([]float64, error) {
+
+	if len(content)%8 != 0 {
+		return nil, fmt.Errorf("expected a float64 array, found %d bytes", len(content))
+	}
+	values := make([]float64, len(content)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(content[8*i:]))
+	}
+	return values, nil
+}
@@ -0,0 +1,77 @@
+package ml
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportWeightsNPYHeader(t *testing.T) {
+
+	model := testModel()
+	fileName := filepath.Join(t.TempDir(), "weights.npy")
+	if err := ExportWeightsNPY(model, fileName); err != nil {
+		t.Fatalf("error exporting weights: %v", err)
+	}
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("error reading weights: %v", err)
+	}
+	if string(content[:6]) != "\x93NUMPY" || content[6] != 1 || content[7] != 0 {
+		t.Fatalf("expected a version 1.0 .npy magic string, found %q", content[:8])
+	}
+	headerLength := int(binary.LittleEndian.Uint16(content[8:]))
+	if (10+headerLength)%64 != 0 {
+		t.Fatalf("expected the header to be 64 byte aligned, found %d bytes", 10+headerLength)
+	}
+	header := string(content[10 : 10+headerLength])
+	if !strings.Contains(header, "'descr': '<f8'") || !strings.Contains(header, "'shape': (3,)") ||
+		!strings.HasSuffix(header, "\n") {
+		t.Fatalf("unexpected header %q", header)
+	}
+	if len(content)-10-headerLength != 8*len(model.Coeficients) {
+		t.Fatalf("expected %d bytes of data, found %d", 8*len(model.Coeficients), len(content)-10-headerLength)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(fileName), "weights.json")); err != nil {
+		t.Fatalf("expected a sidecar metadata file: %v", err)
+	}
+}
+
+func TestImportWeightsRoundTrip(t *testing.T) {
+
+	model, err := LoadModel("testdata/white.model")
+	if err != nil {
+		t.Fatalf("error loading model: %v", err)
+	}
+	fileName := filepath.Join(t.TempDir(), "weights.npy")
+	if err := ExportWeightsNPY(model, fileName); err != nil {
+		t.Fatalf("error exporting weights: %v", err)
+	}
+	imported, err := ImportWeights(fileName)
+	if err != nil {
+		t.Fatalf("error importing weights: %v", err)
+	}
+
+	dataSet, err := ReadCSVDataSet("testdata/wine.csv")
+	if err != nil {
+		t.Fatalf("error reading dataset: %v", err)
+	}
+	for _, example := range normalizeDataSet(model, dataSet) {
+		if Predict(imported, example) != Predict(model, example) {
+			t.Fatalf("expected prediction %v, found %v", Predict(model, example), Predict(imported, example))
+		}
+	}
+
+	//A raw float64 array with the wrong length is rejected
+	rawFileName := filepath.Join(filepath.Dir(fileName), "weights.bin")
+	if err := os.WriteFile(rawFileName, make([]byte, 8*(len(model.Coeficients)-1)), 0644); err != nil {
+		t.Fatalf("error writing weights: %v", err)
+	}
+	if _, err := ImportWeights(rawFileName); !errors.Is(err, ErrFeatureCountMismatch) {
+		t.Fatalf("expected ErrFeatureCountMismatch, found %v", err)
+	}
+}